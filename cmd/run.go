@@ -78,6 +78,19 @@ var cmdRun = &cobra.Command{
 			return errors.New("invalid quarter")
 		}
 
+		// reject unknown phases instead of silently skipping them
+		var phases []string
+		for _, phase := range strings.Split(globalRun.Phases, ",") {
+			if phase = strings.TrimSpace(phase); phase == "" {
+				continue
+			}
+			name, err := wraith.PhaseName(phase)
+			if err != nil {
+				return err
+			}
+			phases = append(phases, name)
+		}
+
 		for ; globalRun.Loops > 0; globalRun.Loops-- {
 			gameFile := filepath.Join(globalRun.Root, globalRun.Game, fmt.Sprintf("%04d", globalRun.Year), fmt.Sprintf("%d", globalRun.Quarter), "game.json")
 			log.Printf("game: %s\n", gameFile)
@@ -111,47 +124,66 @@ var cmdRun = &cobra.Command{
 
 				ordersFile := filepath.Join(filepath.Join(globalRun.Root, globalRun.Game, fmt.Sprintf("%04d", globalRun.Year), fmt.Sprintf("%d", globalRun.Quarter), fmt.Sprintf("%d.orders.txt", player.Id)))
 
+				// a missing orders file is not an error since standing orders may still run
+				var o []*orders.Order
 				b, err := os.ReadFile(ordersFile)
 				if err != nil {
-					player.Log("orders: read: %+v\n\n", err)
-					continue
-				}
-				player.Log("orders: loaded %s\n", ordersFile)
-
-				player.Log("\nOrder Parsing ---------------------------------------------------\n")
-				o, err := orders.Parse([]byte(b))
-				if err != nil {
-					player.Log("  parser error: %+v\n\n", err)
-					continue
-				}
-				foundErrors := false
-				for _, oo := range o {
-					if oo.Reject == nil && len(oo.Errors) == 0 {
+					player.Log("orders: read: %+v\n", err)
+					player.Log("orders: only standing orders will run\n\n")
+				} else {
+					player.Log("orders: loaded %s\n", ordersFile)
+
+					player.Log("\nOrder Parsing ---------------------------------------------------\n")
+					o, err = orders.Parse(b)
+					if err != nil {
+						player.Log("  parser error: %+v\n\n", err)
 						continue
 					}
-					foundErrors = true
-					player.Log("  %d:  %s", oo.Line, oo.Verb.String())
-					for _, arg := range oo.Args {
-						player.Log(" %s", arg)
-					}
-					for _, arg := range oo.Reject {
-						player.Log(" %s", arg)
+					foundErrors := false
+					for _, oo := range o {
+						if oo.Reject == nil && len(oo.Errors) == 0 {
+							continue
+						}
+						foundErrors = true
+						player.Log("  %d:  %s", oo.Line, oo.Verb.String())
+						for _, arg := range oo.Args {
+							player.Log(" %s", arg)
+						}
+						for _, arg := range oo.Reject {
+							player.Log(" %s", arg)
+						}
+						player.Log("\n")
+						for _, err := range oo.Errors {
+							player.Log("        %v\n", err)
+						}
+
+						//log.Printf("  %d: %d:  %s\n", player.Id, oo.Line, oo.Verb.String())
 					}
-					player.Log("\n")
-					for _, err := range oo.Errors {
-						player.Log("        %v\n", err)
+					if !foundErrors {
+						player.Log("  no errors found during initial parse\n")
 					}
+				}
 
-					//log.Printf("  %d: %d:  %s\n", player.Id, oo.Line, oo.Verb.String())
+				// standing orders given this turn replace the saved ones
+				if o, err = adapters.MergeStandingOrders(player, o...); err != nil {
+					player.Log("  standing orders: %+v\n", err)
+				} else if len(player.StandingOrders) != 0 {
+					player.Log("  %d standing orders\n", len(player.StandingOrders))
 				}
-				if !foundErrors {
-					player.Log("  no errors found during initial parse\n")
+
+				// log the sequence the orders will run in so that the results can be explained
+				player.Log("\nOrder Sequence --------------------------------------------------\n")
+				seq := 0
+				for _, oo := range orders.Sequence(o) {
+					if oo.Errors == nil && oo.Reject == nil {
+						seq++
+						player.Log("  %4d: line %4d: %s\n", seq, oo.Line, oo.String())
+					}
 				}
 
 				adapters.OrdersToPhaseOrders(po, o...)
 			}
 
-			phases := strings.Split(globalRun.Phases, ",")
 			err = e.Execute(pos, phases...)
 			if err != nil {
 				log.Fatal(err)
@@ -0,0 +1,140 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mdhender/wraith/internal/adapters"
+	"github.com/mdhender/wraith/internal/orders"
+	"github.com/mdhender/wraith/storage/config"
+	"github.com/mdhender/wraith/storage/jdb"
+	"github.com/spf13/cobra"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var globalValidate struct {
+	Root     string
+	Year     int
+	Quarter  int
+	Game     string
+	PlayerId int
+}
+
+var cmdValidate = &cobra.Command{
+	Use:   "validate",
+	Short: "validate orders",
+	Long:  `Check the orders for a turn against the current state of the game without running them.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if globalBase.ConfigFile == "" {
+			return errors.New("missing config file name")
+		}
+
+		cfg, err := config.LoadGlobal(globalBase.ConfigFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("loaded config %q\n", cfg.Self)
+
+		if globalValidate.Root = strings.TrimSpace(globalValidate.Root); globalValidate.Root == "" {
+			return errors.New("missing path to game files")
+		}
+		globalValidate.Root = filepath.Clean(globalValidate.Root)
+
+		if globalValidate.Game = strings.TrimSpace(globalValidate.Game); globalValidate.Game == "" {
+			return errors.New("missing game name")
+		} else if filepath.Clean(globalValidate.Game) != globalValidate.Game {
+			return errors.New("invalid game name")
+		}
+
+		if !(0 <= globalValidate.Year && globalValidate.Year <= 9999) {
+			return errors.New("invalid year")
+		}
+
+		if !(1 <= globalValidate.Quarter && globalValidate.Quarter <= 4) && !(globalValidate.Year == 0 && globalValidate.Quarter == 0) {
+			return errors.New("invalid quarter")
+		}
+
+		turnPath := filepath.Join(globalValidate.Root, globalValidate.Game, fmt.Sprintf("%04d", globalValidate.Year), fmt.Sprintf("%d", globalValidate.Quarter))
+		jg, err := jdb.Load(filepath.Join(turnPath, "game.json"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		e, err := adapters.JdbGameToWraithEngine(jg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("loaded game %s: turn %04d/%d\n", e.Game.Code, e.Game.Turn.Year, e.Game.Turn.Quarter)
+
+		var ids []int
+		for id := range e.Players {
+			if globalValidate.PlayerId == 0 || globalValidate.PlayerId == id {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			return fmt.Errorf("no such player %d", globalValidate.PlayerId)
+		}
+		sort.Ints(ids)
+
+		foundErrors := false
+		for _, id := range ids {
+			ordersFile := filepath.Join(turnPath, fmt.Sprintf("%d.orders.txt", id))
+			b, err := os.ReadFile(ordersFile)
+			if err != nil {
+				log.Printf("player %d: %v\n", id, err)
+				continue
+			}
+			o, err := orders.Parse(b)
+			if err != nil {
+				log.Printf("player %d: %s: %v\n", id, ordersFile, err)
+				foundErrors = true
+				continue
+			}
+			adapters.ValidateOrders(e, e.Players[id], o...)
+			for _, d := range orders.Diagnostics(o) {
+				fmt.Printf("%s:%d:%d: %s: %s: %s\n", ordersFile, d.Line, d.Col, d.Severity, d.Code, d.Message)
+				foundErrors = foundErrors || d.Severity == orders.SeverityError
+			}
+		}
+		if foundErrors {
+			return errors.New("orders have errors")
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	cmdValidate.Flags().StringVar(&globalValidate.Root, "root", "", "path to game files")
+	_ = cmdValidate.MarkFlagRequired("root")
+	cmdValidate.Flags().StringVar(&globalValidate.Game, "game", "", "game to validate orders for")
+	_ = cmdValidate.MarkFlagRequired("game")
+	cmdValidate.Flags().IntVar(&globalValidate.Year, "year", 0, "turn year")
+	_ = cmdValidate.MarkFlagRequired("year")
+	cmdValidate.Flags().IntVar(&globalValidate.Quarter, "quarter", 0, "turn quarter")
+	_ = cmdValidate.MarkFlagRequired("quarter")
+	cmdValidate.Flags().IntVar(&globalValidate.PlayerId, "player", 0, "id of player to validate (default all players)")
+
+	cmdBase.AddCommand(cmdValidate)
+}
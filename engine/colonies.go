@@ -19,9 +19,12 @@
 package engine
 
 type Colony struct {
-	Id           string  // key, format is always C# where # is an integer
-	ControlledBy *Player // nil only if colony is not controlled
-	Name         string
+	Id            string  // key, format is always C# where # is an integer
+	ControlledBy  *Player // nil only if colony is not controlled
+	Name          string
+	Location      *Planet // nil only if the location is not known
+	FactoryGroups []*FactoryGroup
+	MiningGroups  []*MiningGroup
 }
 
 type XColony struct {
@@ -65,16 +65,15 @@ type RetoolPhaseOrder struct {
 	MiningGroup  *RetoolMiningGroupOrder
 }
 type RetoolFactoryGroupOrder struct {
-	CorS     string // id of ship or colony to assemble in
-	Quantity int
-	Unit     string
-	Product  string
+	CorS      string // id of ship or colony the group is in
+	Group     string // id of factory group to retool
+	Product   string // code of unit the group will produce
+	TechLevel int    // tech level of unit the group will produce
 }
 type RetoolMiningGroupOrder struct {
-	CorS     string // id of ship or colony to assemble in
-	Quantity int
-	Unit     string
-	Product  string
+	CorS    string // id of colony the group is in
+	Group   string // id of mining group to retool
+	Deposit string // id of deposit the group will mine
 }
 
 type ControlPhaseOrder struct {
@@ -279,10 +278,101 @@ func (o *NameShipOrder) Execute(e *Engine, p *Player) error {
 func (e *Engine) ExecuteRetoolPhase(pos []*PhaseOrders) []error {
 	var errs []error
 	for _, po := range pos {
-		if len(po.Retool) == 0 {
-			continue
+		for _, order := range po.Retool {
+			if err := order.FactoryGroup.Execute(e, po.Player); err != nil {
+				errs = append(errs, err)
+			}
+			if err := order.MiningGroup.Execute(e, po.Player); err != nil {
+				errs = append(errs, err)
+			}
 		}
-		log.Printf("execute: %s: retool\n", po.Player.Handle)
 	}
 	return errs
 }
+
+// Execute switches a factory group to a new product.
+// Work in progress is scrapped.
+// Will fail if the colony or ship is not controlled by the player.
+func (o *RetoolFactoryGroupOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	log.Printf("execute: %s: retool: %s %s %s-%d\n", p.Handle, o.CorS, o.Group, o.Product, o.TechLevel)
+	// find colony or ship
+	var groups []*FactoryGroup
+	if c, ok := e.findColony(o.CorS); ok {
+		// fail if controlled by another player
+		if c.ControlledBy != p {
+			return fmt.Errorf("no such colony %q", o.CorS)
+		}
+		groups = c.FactoryGroups
+	} else if s, ok := e.findShip(o.CorS); ok {
+		// fail if controlled by another player
+		if s.ControlledBy != p {
+			return fmt.Errorf("no such ship %q", o.CorS)
+		}
+		groups = s.FactoryGroups
+	} else {
+		return fmt.Errorf("no such colony or ship %q", o.CorS)
+	}
+	// find group
+	for _, g := range groups {
+		if o.Group == fmt.Sprintf("FG%d", g.No) {
+			// update the product and scrap the work in progress
+			g.BuildCode, g.BuildTechLevel = o.Product, o.TechLevel
+			for _, u := range g.Units {
+				for i := range u.Stages {
+					u.Stages[i] = 0
+				}
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("no such factory group %q", o.Group)
+}
+
+// Execute points a mining group at a new deposit on the colony's planet.
+// Work in progress is scrapped.
+// Will fail if the colony is not controlled by the player.
+func (o *RetoolMiningGroupOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	log.Printf("execute: %s: retool: %s %s %s\n", p.Handle, o.CorS, o.Group, o.Deposit)
+	// find colony
+	c, ok := e.findColony(o.CorS)
+	if !ok {
+		return fmt.Errorf("no such colony %q", o.CorS)
+	}
+	// fail if controlled by another player
+	if c.ControlledBy != p {
+		return fmt.Errorf("no such colony %q", o.CorS)
+	}
+	// find deposit
+	var deposit *NaturalResource
+	if c.Location != nil {
+		for _, r := range c.Location.Resources {
+			if o.Deposit == fmt.Sprintf("DP%d", r.No) {
+				deposit = r
+				break
+			}
+		}
+	}
+	if deposit == nil {
+		return fmt.Errorf("no such deposit %q", o.Deposit)
+	}
+	// find group
+	for _, g := range c.MiningGroups {
+		if o.Group == fmt.Sprintf("MG%d", g.No) {
+			// update the deposit and scrap the work in progress
+			g.Deposit = deposit
+			for _, u := range g.Units {
+				for i := range u.Stages {
+					u.Stages[i] = 0
+				}
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("no such mining group %q", o.Group)
+}
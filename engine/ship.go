@@ -19,9 +19,10 @@
 package engine
 
 type Ship struct {
-	Id           string  // key, format is always S# where # is an integer
-	ControlledBy *Player // nil only if ship is not controlled
-	Name         string
+	Id            string  // key, format is always S# where # is an integer
+	ControlledBy  *Player // nil only if ship is not controlled
+	Name          string
+	FactoryGroups []*FactoryGroup
 }
 
 type XShip struct {
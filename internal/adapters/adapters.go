@@ -20,12 +20,14 @@
 package adapters
 
 import (
+	"errors"
 	"fmt"
 	"github.com/mdhender/wraith/engine"
 	"github.com/mdhender/wraith/internal/orders"
 	"github.com/mdhender/wraith/internal/tokens"
 	"github.com/mdhender/wraith/models"
 	"github.com/mdhender/wraith/wraith"
+	"strings"
 )
 
 func ModelsColoniesToEngineColonies(mc []*models.ColonyOrShip) []*engine.Colony {
@@ -60,12 +62,19 @@ func ModelsPlayerToEnginePlayer(mp *models.Player) *engine.Player {
 }
 
 // OrdersToPhaseOrders converts orders into the Engine's expected format while splitting them into buckets for each phase.
-// Because it appends to the bucket, it does not change the relative order of commands in a phase.
+// The orders are put in priority sequence first (see orders.Sequence). Because it appends to the bucket,
+// it does not change the relative order of commands with the same priority in a phase.
 // Invalid or unknown orders are dropped.
 func OrdersToPhaseOrders(epo *wraith.PhaseOrders, o ...*orders.Order) *wraith.PhaseOrders {
-	for _, order := range o {
+	for _, order := range orders.Sequence(o) {
 		if order == nil || order.Verb == nil || order.Errors != nil || order.Reject != nil {
 			continue
+		} else if order.Condition != nil {
+			// convert the order by itself so that the condition can be attached to it
+			unconditional := *order
+			unconditional.Condition = nil
+			epo.AddConditional(OrdersToPhaseOrders(&wraith.PhaseOrders{Player: epo.Player}, &unconditional), orderToCondition(order))
+			continue
 		}
 		switch order.Verb.Kind {
 		case tokens.AssembleConstructionCrew:
@@ -103,6 +112,35 @@ func OrdersToPhaseOrders(epo *wraith.PhaseOrders, o ...*orders.Order) *wraith.Ph
 				Quantity: order.Args[1].Integer,
 			}
 			epo.Assembly = append(epo.Assembly, &wraith.AssemblyPhaseOrder{SpyTeam: o})
+		case tokens.Attack:
+			epo.Combat = append(epo.Combat, &wraith.CombatPhaseOrder{Attack: &wraith.AttackOrder{
+				CorS:   string(order.Args[0].Text),
+				Target: string(order.Args[1].Text),
+			}})
+		case tokens.BuildColony:
+			loc := order.Args[1].Location
+			epo.Build = append(epo.Build, &wraith.BuildPhaseOrder{Colony: &wraith.BuildColonyOrder{
+				CorS:     string(order.Args[0].Text),
+				Coords:   wraith.Coordinates{X: loc.X, Y: loc.Y, Z: loc.Z},
+				Star:     loc.Star,
+				OrbitNo:  loc.OrbitNo,
+				Kind:     string(order.Args[2].Text),
+				Quantity: order.Args[3].Integer,
+				Unit:     order.Args[4].String(),
+			}})
+		case tokens.BuildShip:
+			epo.Build = append(epo.Build, &wraith.BuildPhaseOrder{Ship: &wraith.BuildShipOrder{
+				CorS:     string(order.Args[0].Text),
+				Quantity: order.Args[1].Integer,
+				Unit:     order.Args[2].String(),
+			}})
+		case tokens.Buy:
+			epo.Trade = append(epo.Trade, &wraith.TradePhaseOrder{Buy: &wraith.BuyOrder{
+				CorS:     string(order.Args[0].Text),
+				Quantity: order.Args[1].Integer,
+				Unit:     order.Args[2].String(),
+				Price:    order.Args[3].Integer,
+			}})
 		case tokens.Control:
 			id := string(order.Args[0].Text)
 			if order.Args[0].Kind == tokens.ColonyId {
@@ -110,6 +148,60 @@ func OrdersToPhaseOrders(epo *wraith.PhaseOrders, o ...*orders.Order) *wraith.Ph
 			} else if order.Args[0].Kind == tokens.ShipId {
 				epo.Control = append(epo.Control, &wraith.ControlPhaseOrder{ControlShip: &wraith.ControlShipOrder{Id: id}})
 			}
+		case tokens.CounterIntel:
+			epo.Espionage = append(epo.Espionage, &wraith.EspionagePhaseOrder{CounterIntel: &wraith.CounterIntelOrder{
+				CorS:     string(order.Args[0].Text),
+				Quantity: order.Args[1].Integer,
+			}})
+		case tokens.Defend:
+			epo.Combat = append(epo.Combat, &wraith.CombatPhaseOrder{Defend: &wraith.DefendOrder{CorS: string(order.Args[0].Text)}})
+		case tokens.Disassemble:
+			epo.Disassembly = append(epo.Disassembly, &wraith.DisassembleOrder{
+				CorS:     string(order.Args[0].Text),
+				Quantity: order.Args[1].Integer,
+				Unit:     order.Args[2].String(),
+			})
+		case tokens.Draft:
+			epo.Draft = append(epo.Draft, &wraith.DraftOrder{
+				CorS:     string(order.Args[0].Text),
+				Quantity: order.Args[1].Integer,
+				Class:    populationClass(order.Args[2]),
+			})
+		case tokens.GatherIntel:
+			epo.Espionage = append(epo.Espionage, &wraith.EspionagePhaseOrder{GatherIntel: &wraith.GatherIntelOrder{
+				CorS:     string(order.Args[0].Text),
+				Quantity: order.Args[1].Integer,
+				Target:   string(order.Args[2].Text),
+			}})
+		case tokens.Incite:
+			epo.Espionage = append(epo.Espionage, &wraith.EspionagePhaseOrder{Incite: &wraith.InciteOrder{
+				CorS:     string(order.Args[0].Text),
+				Quantity: order.Args[1].Integer,
+				Target:   string(order.Args[2].Text),
+			}})
+		case tokens.Jump:
+			loc := order.Args[1].Location
+			epo.Movement = append(epo.Movement, &wraith.MovementPhaseOrder{Jump: &wraith.JumpShipOrder{
+				Id:      string(order.Args[0].Text),
+				Coords:  wraith.Coordinates{X: loc.X, Y: loc.Y, Z: loc.Z},
+				Star:    loc.Star,
+				OrbitNo: loc.OrbitNo,
+			}})
+		case tokens.Maintain:
+			epo.Maintain = append(epo.Maintain, &wraith.MaintainOrder{
+				CorS: string(order.Args[0].Text),
+				Unit: order.Args[1].String(),
+			})
+		case tokens.Move:
+			o := &wraith.MoveShipOrder{Id: string(order.Args[0].Text)}
+			if order.Args[1].Kind == tokens.LocationId {
+				loc := order.Args[1].Location
+				o.Coords = &wraith.Coordinates{X: loc.X, Y: loc.Y, Z: loc.Z}
+				o.Star, o.OrbitNo = loc.Star, loc.OrbitNo
+			} else {
+				o.OrbitNo = order.Args[1].Integer
+			}
+			epo.Movement = append(epo.Movement, &wraith.MovementPhaseOrder{Move: o})
 		case tokens.Name:
 			id := string(order.Args[0].Text)
 			name := string(order.Args[1].Text)
@@ -118,7 +210,212 @@ func OrdersToPhaseOrders(epo *wraith.PhaseOrders, o ...*orders.Order) *wraith.Ph
 			} else if order.Args[0].Kind == tokens.ShipId {
 				epo.Control = append(epo.Control, &wraith.ControlPhaseOrder{NameShip: &wraith.NameShipOrder{Id: id, Name: name}})
 			}
+		case tokens.Offer:
+			epo.Trade = append(epo.Trade, &wraith.TradePhaseOrder{Offer: &wraith.OfferOrder{
+				CorS:         string(order.Args[0].Text),
+				Quantity:     order.Args[1].Integer,
+				Unit:         order.Args[2].String(),
+				Partner:      string(order.Args[3].Text),
+				WantQuantity: order.Args[4].Integer,
+				WantUnit:     order.Args[5].String(),
+			}})
+		case tokens.Pay:
+			epo.Pay = append(epo.Pay, &wraith.PayOrder{
+				CorS:  string(order.Args[0].Text),
+				Class: populationClass(order.Args[1]),
+				Pct:   order.Args[2].Number / 100,
+			})
+		case tokens.Raid:
+			epo.Combat = append(epo.Combat, &wraith.CombatPhaseOrder{Raid: &wraith.RaidOrder{
+				CorS:   string(order.Args[0].Text),
+				Target: string(order.Args[1].Text),
+				Cargo:  order.Args[2].String(),
+			}})
+		case tokens.Ration:
+			epo.Ration = append(epo.Ration, &wraith.RationOrder{
+				CorS:  string(order.Args[0].Text),
+				Class: populationClass(order.Args[1]),
+				Pct:   order.Args[2].Number / 100,
+			})
+		case tokens.Research:
+			epo.Research = append(epo.Research, &wraith.ResearchOrder{Target: order.Args[0].String()})
+		case tokens.RetoolFactoryGroup:
+			epo.Retool = append(epo.Retool, &wraith.RetoolPhaseOrder{FactoryGroup: &wraith.RetoolFactoryGroupOrder{
+				CorS:    string(order.Args[0].Text),
+				Group:   string(order.Args[1].Text),
+				Product: order.Args[2].String(),
+			}})
+		case tokens.RetoolMineGroup:
+			epo.Retool = append(epo.Retool, &wraith.RetoolPhaseOrder{MiningGroup: &wraith.RetoolMiningGroupOrder{
+				CorS:    string(order.Args[0].Text),
+				Group:   string(order.Args[1].Text),
+				Deposit: string(order.Args[2].Text),
+			}})
+		case tokens.Sabotage:
+			epo.Espionage = append(epo.Espionage, &wraith.EspionagePhaseOrder{Sabotage: &wraith.SabotageOrder{
+				CorS:     string(order.Args[0].Text),
+				Quantity: order.Args[1].Integer,
+				Target:   string(order.Args[2].Text),
+				Group:    string(order.Args[3].Text),
+			}})
+		case tokens.Sell:
+			epo.Trade = append(epo.Trade, &wraith.TradePhaseOrder{Sell: &wraith.SellOrder{
+				CorS:     string(order.Args[0].Text),
+				Quantity: order.Args[1].Integer,
+				Unit:     order.Args[2].String(),
+				Price:    order.Args[3].Integer,
+			}})
+		case tokens.Setup:
+			epo.SetUp = append(epo.SetUp, &wraith.SetUpOrder{
+				CorS:     string(order.Args[0].Text),
+				Quantity: order.Args[1].Integer,
+				Unit:     order.Args[2].String(),
+			})
+		case tokens.Survey:
+			loc := order.Args[1].Location
+			epo.Survey = append(epo.Survey, &wraith.SurveyOrder{
+				CorS:    string(order.Args[0].Text),
+				Coords:  wraith.Coordinates{X: loc.X, Y: loc.Y, Z: loc.Z},
+				Star:    loc.Star,
+				OrbitNo: loc.OrbitNo,
+			})
+		case tokens.Transfer:
+			from, to, qty := string(order.Args[0].Text), string(order.Args[1].Text), order.Args[2].Integer
+			switch order.Args[3].Kind {
+			case tokens.Professional, tokens.Soldier, tokens.Unemployed, tokens.Unskilled, tokens.ConstructionCrew, tokens.SpyTeam:
+				epo.Transfer = append(epo.Transfer, &wraith.TransferPhaseOrder{Population: &wraith.TransferPopulationOrder{
+					From:     from,
+					To:       to,
+					Quantity: qty,
+					Class:    populationClass(order.Args[3]),
+				}})
+			default:
+				epo.Transfer = append(epo.Transfer, &wraith.TransferPhaseOrder{Unit: &wraith.TransferUnitOrder{
+					From:     from,
+					To:       to,
+					Quantity: qty,
+					Unit:     order.Args[3].String(),
+				}})
+			}
 		}
 	}
 	return epo
 }
+
+// orderToCondition returns the engine's version of the order's condition.
+// The condition tests the inventory of the first colony or ship in the order.
+func orderToCondition(order *orders.Order) *wraith.Condition {
+	c := &wraith.Condition{
+		Unit:     string(order.Condition.Unit.Text),
+		Op:       string(order.Condition.Op.Text),
+		Quantity: order.Condition.Quantity.Integer,
+	}
+	for _, arg := range order.Args {
+		if arg.Kind == tokens.ColonyId || arg.Kind == tokens.ShipId {
+			c.CorS = string(arg.Text)
+			break
+		}
+	}
+	return c
+}
+
+// populationClass returns the engine's name for a population class.
+// The class may be given by name or by code (PRO, SLD, USK, or UEM).
+func populationClass(t *tokens.Token) string {
+	switch t.Kind {
+	case tokens.Professional:
+		return "professional"
+	case tokens.Soldier:
+		return "soldier"
+	case tokens.Unemployed:
+		return "unemployed"
+	case tokens.Unskilled:
+		return "unskilled"
+	}
+	return t.String()
+}
+
+// MergeStandingOrders returns the orders to run for the turn.
+// Standing orders given this turn replace the player's saved standing orders.
+// An "every turn" with no order clears the saved standing orders.
+// If neither was given, the saved standing orders are added to this turn's orders.
+func MergeStandingOrders(p *wraith.Player, o ...*orders.Order) ([]*orders.Order, error) {
+	var standing []string
+	cleared := false
+	for _, order := range o {
+		if order == nil || order.Verb == nil || order.Errors != nil || order.Reject != nil {
+			continue
+		} else if order.Verb.Kind == tokens.Every {
+			cleared = true
+		} else if order.Standing {
+			standing = append(standing, order.String())
+		}
+	}
+	if standing != nil || cleared {
+		p.StandingOrders = standing
+		return o, nil
+	} else if len(p.StandingOrders) == 0 {
+		return o, nil
+	}
+	saved, err := orders.Parse([]byte(strings.Join(p.StandingOrders, "\n")))
+	if err != nil {
+		return o, err
+	}
+	// a saved order that no longer parses is dropped, so tell the player about it
+	for _, order := range saved {
+		if order.Errors == nil || order.Line < 1 || order.Line > len(p.StandingOrders) {
+			continue
+		}
+		for _, err := range order.Errors {
+			p.Log("standing order %q dropped: %v\n", p.StandingOrders[order.Line-1], err)
+		}
+	}
+	return append(o, saved...), nil
+}
+
+// ValidateOrders checks each order that parsed cleanly against the current state of the game.
+// The orders are checked together, in the order the engine will run them, so an order
+// that asks for more than the earlier orders left over is reported.
+// Problems are added to the order's Diagnostics. The order's Errors are not changed,
+// so the order will still be passed to the engine when the turn runs.
+// Shortfalls are reported as warnings since the engine will try to fill the order with what it has.
+func ValidateOrders(e *wraith.Engine, p *wraith.Player, o ...*orders.Order) []*orders.Diagnostic {
+	// convert the orders one at a time so that errors can be traced back to the order
+	po, source := &wraith.PhaseOrders{Player: p}, make(map[interface{}]*orders.Order)
+	for _, order := range orders.Sequence(o) {
+		if order == nil || order.Verb == nil || order.Errors != nil || order.Reject != nil {
+			continue
+		}
+		for _, added := range po.Add(OrdersToPhaseOrders(&wraith.PhaseOrders{Player: p}, order)) {
+			source[added] = order
+		}
+	}
+	var list []*orders.Diagnostic
+	for _, err := range e.Validate(po) {
+		var oe *wraith.OrderError
+		if !errors.As(err, &oe) {
+			continue
+		}
+		order, ok := source[oe.Order]
+		if !ok {
+			continue
+		}
+		d := &orders.Diagnostic{Line: order.Line, Severity: orders.SeverityError, Message: err.Error()}
+		d.Col, d.EndCol = order.Span()
+		switch {
+		case errors.Is(err, wraith.ErrShortfall):
+			d.Code, d.Severity = orders.CodeShortfall, orders.SeverityWarning
+		case errors.Is(err, wraith.ErrInfeasible):
+			d.Code = orders.CodeNotFeasible
+		case errors.Is(err, wraith.ErrNotCoLocated):
+			d.Code = orders.CodeNotCoLocated
+		case errors.Is(err, wraith.ErrNotControlled):
+			d.Code = orders.CodeNotControlled
+		default:
+			d.Code = orders.CodeNotFound
+		}
+		order.Diagnostics = append(order.Diagnostics, d)
+		list = append(list, d)
+	}
+	return list
+}
@@ -59,6 +59,19 @@ func WraithEngineToJdbGame(e *wraith.Engine) *jdb.Game {
 	}
 	sort.Sort(jg.Deposits)
 
+	for _, report := range e.Market {
+		jg.Market = append(jg.Market, &jdb.MarketReport{
+			UnitId:    report.Unit.Id,
+			BidQty:    report.BidQty,
+			AskQty:    report.AskQty,
+			Volume:    report.Volume,
+			LowPrice:  report.LowPrice,
+			HighPrice: report.HighPrice,
+			AvgPrice:  report.AvgPrice,
+		})
+	}
+	sort.Sort(jg.Market)
+
 	for _, nation := range e.Nations {
 		n := &jdb.Nation{
 			Id:                 nation.Id,
@@ -84,6 +97,10 @@ func WraithEngineToJdbGame(e *wraith.Engine) *jdb.Game {
 		n.Skills.Military = nation.Skills.Military
 		n.Skills.Mining = nation.Skills.Mining
 		n.Skills.Shields = nation.Skills.Shields
+		for _, survey := range nation.Surveys {
+			n.Surveys = append(n.Surveys, wraithSurveyToJdbSurvey(survey))
+		}
+		sort.Sort(n.Surveys)
 
 		jg.Nations = append(jg.Nations, n)
 	}
@@ -129,10 +146,14 @@ func WraithEngineToJdbGame(e *wraith.Engine) *jdb.Game {
 
 	for _, player := range e.Players {
 		p := &jdb.Player{
-			Id:       player.Id,
-			UserId:   player.UserId,
-			Name:     player.Name,
-			MemberOf: player.MemberOf.Id,
+			Id:              player.Id,
+			UserId:          player.UserId,
+			Name:            player.Name,
+			MemberOf:        player.MemberOf.Id,
+			CombatReport:    player.CombatReport,
+			EspionageReport: player.EspionageReport,
+			UnrestReport:    player.UnrestReport,
+			StandingOrders:  player.StandingOrders,
 		}
 		if player.ReportsTo != nil {
 			p.ReportsToPlayerId = player.ReportsTo.Id
@@ -179,19 +200,23 @@ func WraithEngineToJdbGame(e *wraith.Engine) *jdb.Game {
 
 	for _, unit := range e.Units {
 		u := &jdb.Unit{
-			Id:                  unit.Id,
-			Kind:                unit.Kind,
-			Code:                unit.Code,
-			TechLevel:           unit.TechLevel,
-			Name:                unit.Name,
-			Description:         unit.Description,
-			MassPerUnit:         unit.MassPerUnit,
-			VolumePerUnit:       unit.VolumePerUnit,
-			Hudnut:              unit.Hudnut,
-			StowedVolumePerUnit: unit.StowedVolumePerUnit,
-			FuelPerUnitPerTurn:  unit.FuelPerUnitPerTurn,
-			MetsPerUnit:         unit.MetsPerUnitPerTurn,
-			NonMetsPerUnit:      unit.NonMetsPerUnitPerTurn,
+			Id:                        unit.Id,
+			Kind:                      unit.Kind,
+			Code:                      unit.Code,
+			TechLevel:                 unit.TechLevel,
+			Name:                      unit.Name,
+			Description:               unit.Description,
+			MassPerUnit:               unit.MassPerUnit,
+			VolumePerUnit:             unit.VolumePerUnit,
+			Hudnut:                    unit.Hudnut,
+			StowedVolumePerUnit:       unit.StowedVolumePerUnit,
+			FuelPerUnitPerTurn:        unit.FuelPerUnitPerTurn,
+			FuelPerUnitPerCombatRound: unit.FuelPerUnitPerCombatRound,
+			MetsPerUnit:               unit.MetsPerUnitPerTurn,
+			NonMetsPerUnit:            unit.NonMetsPerUnitPerTurn,
+			UpkeepMetsPerUnit:         unit.UpkeepMetsPerUnitPerTurn,
+			UpkeepNmtsPerUnit:         unit.UpkeepNmtsPerUnitPerTurn,
+			UpkeepCngdPerUnit:         unit.UpkeepCngdPerUnitPerTurn,
 		}
 		jg.Units = append(jg.Units, u)
 	}
@@ -248,6 +273,7 @@ func wraithColonyToJdbEnclosedColony(colony *wraith.CorS, game *jdb.Game) *jdb.E
 			Stage2Qty: group.StageQty[1],
 			Stage3Qty: group.StageQty[2],
 			Stage4Qty: group.StageQty[3],
+			IdleTurns: group.IdleTurns,
 		}
 		for _, unit := range group.Units {
 			fg.Units = append(fg.Units, &jdb.FactoryGroupUnits{
@@ -308,6 +334,7 @@ func wraithColonyToJdbEnclosedColony(colony *wraith.CorS, game *jdb.Game) *jdb.E
 			Stage2Qty: group.StageQty[1],
 			Stage3Qty: group.StageQty[2],
 			Stage4Qty: group.StageQty[3],
+			IdleTurns: group.IdleTurns,
 		}
 		game.MineGroups = append(game.MineGroups, fg)
 	}
@@ -325,6 +352,10 @@ func wraithColonyToJdbEnclosedColony(colony *wraith.CorS, game *jdb.Game) *jdb.E
 	c.Population.RebelPct = colony.Population.RebelPct
 	c.Population.BirthsPriorTurn = colony.Population.BirthsPriorTurn
 	c.Population.NaturalDeathsPriorTurn = colony.Population.NaturalDeathsPriorTurn
+	c.Population.ImmigrantsPriorTurn = colony.Population.ImmigrantsPriorTurn
+	c.Population.EmigrantsPriorTurn = colony.Population.EmigrantsPriorTurn
+	c.Population.StandardOfLiving = colony.Population.StandardOfLiving
+	c.Population.Training = wraithTraineesToJdbTrainees(colony.Population.Training)
 
 	c.Rations.ProfessionalPct = colony.Rations.ProfessionalPct
 	c.Rations.SoldierPct = colony.Rations.SoldierPct
@@ -358,6 +389,7 @@ func wraithColonyToJdbOrbitalColony(colony *wraith.CorS, game *jdb.Game) *jdb.Or
 			Stage2Qty: group.StageQty[1],
 			Stage3Qty: group.StageQty[2],
 			Stage4Qty: group.StageQty[3],
+			IdleTurns: group.IdleTurns,
 		}
 		for _, unit := range group.Units {
 			fg.Units = append(fg.Units, &jdb.FactoryGroupUnits{
@@ -418,6 +450,10 @@ func wraithColonyToJdbOrbitalColony(colony *wraith.CorS, game *jdb.Game) *jdb.Or
 	c.Population.RebelPct = colony.Population.RebelPct
 	c.Population.BirthsPriorTurn = colony.Population.BirthsPriorTurn
 	c.Population.NaturalDeathsPriorTurn = colony.Population.NaturalDeathsPriorTurn
+	c.Population.ImmigrantsPriorTurn = colony.Population.ImmigrantsPriorTurn
+	c.Population.EmigrantsPriorTurn = colony.Population.EmigrantsPriorTurn
+	c.Population.StandardOfLiving = colony.Population.StandardOfLiving
+	c.Population.Training = wraithTraineesToJdbTrainees(colony.Population.Training)
 
 	c.Rations.ProfessionalPct = colony.Rations.ProfessionalPct
 	c.Rations.SoldierPct = colony.Rations.SoldierPct
@@ -452,6 +488,7 @@ func wraithColonyToJdbSurfaceColony(colony *wraith.CorS, game *jdb.Game) *jdb.Su
 			Stage2Qty: group.StageQty[1],
 			Stage3Qty: group.StageQty[2],
 			Stage4Qty: group.StageQty[3],
+			IdleTurns: group.IdleTurns,
 		}
 		for _, unit := range group.Units {
 			fg.Units = append(fg.Units, &jdb.FactoryGroupUnits{
@@ -516,6 +553,7 @@ func wraithColonyToJdbSurfaceColony(colony *wraith.CorS, game *jdb.Game) *jdb.Su
 			Stage2Qty: group.StageQty[1],
 			Stage3Qty: group.StageQty[2],
 			Stage4Qty: group.StageQty[3],
+			IdleTurns: group.IdleTurns,
 		}
 		game.MineGroups = append(game.MineGroups, fg)
 	}
@@ -535,6 +573,10 @@ func wraithColonyToJdbSurfaceColony(colony *wraith.CorS, game *jdb.Game) *jdb.Su
 	c.Population.RebelPct = colony.Population.RebelPct
 	c.Population.BirthsPriorTurn = colony.Population.BirthsPriorTurn
 	c.Population.NaturalDeathsPriorTurn = colony.Population.NaturalDeathsPriorTurn
+	c.Population.ImmigrantsPriorTurn = colony.Population.ImmigrantsPriorTurn
+	c.Population.EmigrantsPriorTurn = colony.Population.EmigrantsPriorTurn
+	c.Population.StandardOfLiving = colony.Population.StandardOfLiving
+	c.Population.Training = wraithTraineesToJdbTrainees(colony.Population.Training)
 
 	c.Rations.ProfessionalPct = colony.Rations.ProfessionalPct
 	c.Rations.SoldierPct = colony.Rations.SoldierPct
@@ -569,6 +611,7 @@ func wraithShipToJdbShip(ship *wraith.CorS, game *jdb.Game) *jdb.Ship {
 			Stage2Qty: group.StageQty[1],
 			Stage3Qty: group.StageQty[2],
 			Stage4Qty: group.StageQty[3],
+			IdleTurns: group.IdleTurns,
 		}
 		for _, unit := range group.Units {
 			fg.Units = append(fg.Units, &jdb.FactoryGroupUnits{
@@ -627,6 +670,11 @@ func wraithShipToJdbShip(ship *wraith.CorS, game *jdb.Game) *jdb.Ship {
 	s.Population.ConstructionCrewQty = ship.Population.ConstructionCrewQty
 	s.Population.SpyTeamQty = ship.Population.SpyTeamQty
 	s.Population.RebelPct = ship.Population.RebelPct
+	s.Population.NaturalDeathsPriorTurn = ship.Population.NaturalDeathsPriorTurn
+	s.Population.ImmigrantsPriorTurn = ship.Population.ImmigrantsPriorTurn
+	s.Population.EmigrantsPriorTurn = ship.Population.EmigrantsPriorTurn
+	s.Population.StandardOfLiving = ship.Population.StandardOfLiving
+	s.Population.Training = wraithTraineesToJdbTrainees(ship.Population.Training)
 
 	s.Rations.ProfessionalPct = ship.Rations.ProfessionalPct
 	s.Rations.SoldierPct = ship.Rations.SoldierPct
@@ -635,3 +683,29 @@ func wraithShipToJdbShip(ship *wraith.CorS, game *jdb.Game) *jdb.Ship {
 
 	return s
 }
+
+func wraithTraineesToJdbTrainees(training []*wraith.Trainees) (trainees jdb.Trainees) {
+	for _, t := range training {
+		trainees = append(trainees, &jdb.Trainee{Class: t.Class, Qty: t.Qty, TurnsLeft: t.TurnsLeft})
+	}
+	return trainees
+}
+
+func wraithSurveyToJdbSurvey(survey *wraith.Survey) *jdb.Survey {
+	s := &jdb.Survey{
+		PlanetId:       survey.Planet.Id,
+		Year:           survey.Year,
+		Quarter:        survey.Quarter,
+		Kind:           survey.Kind,
+		HabitabilityNo: survey.HabitabilityNo,
+	}
+	for _, d := range survey.Deposits {
+		s.Deposits = append(s.Deposits, &jdb.SurveyDeposit{
+			No:           d.No,
+			UnitId:       d.Product.Id,
+			YieldPct:     d.YieldPct,
+			RemainingQty: d.RemainingQty,
+		})
+	}
+	return s
+}
@@ -72,9 +72,13 @@ func JdbGameToWraithEngine(jg *jdb.Game) (*wraith.Engine, error) {
 	// first loop creates the struct.
 	for _, player := range jg.Players {
 		e.Players[player.Id] = &wraith.Player{
-			Id:     player.Id,
-			UserId: player.UserId,
-			Name:   player.Name,
+			Id:              player.Id,
+			UserId:          player.UserId,
+			Name:            player.Name,
+			CombatReport:    player.CombatReport,
+			EspionageReport: player.EspionageReport,
+			UnrestReport:    player.UnrestReport,
+			StandingOrders:  player.StandingOrders,
 		}
 	}
 	// second loop links players to rulers.
@@ -107,8 +111,20 @@ func JdbGameToWraithEngine(jg *jdb.Game) (*wraith.Engine, error) {
 		d.Planet.Deposits = append(d.Planet.Deposits, d)
 	}
 
+	for _, report := range jg.Market {
+		e.Market = append(e.Market, &wraith.MarketReport{
+			Unit:      e.Units[report.UnitId],
+			BidQty:    report.BidQty,
+			AskQty:    report.AskQty,
+			Volume:    report.Volume,
+			LowPrice:  report.LowPrice,
+			HighPrice: report.HighPrice,
+			AvgPrice:  report.AvgPrice,
+		})
+	}
+
 	for _, nation := range jg.Nations {
-		n := jdbNationToWraithNation(nation, e.Players, e.Planets)
+		n := jdbNationToWraithNation(nation, e.Players, e.Planets, e.Units)
 		e.Nations[n.Id] = n
 		e.Players[nation.ControlledByPlayerId].MemberOf = n
 	}
@@ -177,7 +193,7 @@ func JdbGameToWraithEngine(jg *jdb.Game) (*wraith.Engine, error) {
 
 	for _, ship := range e.Ships {
 		if ship.ControlledBy != nil {
-			ship.ControlledBy.Colonies = append(ship.ControlledBy.Colonies, ship)
+			ship.ControlledBy.Ships = append(ship.ControlledBy.Ships, ship)
 		}
 		ship.Planet.Ships = append(ship.Planet.Ships, ship)
 	}
@@ -248,6 +264,9 @@ func JdbGameToWraithEngine(jg *jdb.Game) (*wraith.Engine, error) {
 			e.Seq = u.Id
 		}
 	}
+
+	e.SeedHomeSurveys()
+
 	return e, nil
 }
 
@@ -285,6 +304,10 @@ func jdbEnclosedColonyToWraithColony(colony *jdb.EnclosedColony, factoryGroup ma
 			RebelPct:               colony.Population.RebelPct,
 			BirthsPriorTurn:        colony.Population.BirthsPriorTurn,
 			NaturalDeathsPriorTurn: colony.Population.NaturalDeathsPriorTurn,
+			ImmigrantsPriorTurn:    colony.Population.ImmigrantsPriorTurn,
+			EmigrantsPriorTurn:     colony.Population.EmigrantsPriorTurn,
+			StandardOfLiving:       colony.Population.StandardOfLiving,
+			Training:               jdbTraineesToWraithTrainees(colony.Population.Training),
 		},
 		Pay: wraith.Pay{
 			ProfessionalPct: colony.Pay.ProfessionalPct,
@@ -334,11 +357,12 @@ func jdbEnclosedColonyToWraithColony(colony *jdb.EnclosedColony, factoryGroup ma
 
 func jdbFactoryGroupToWraithFactoryGroup(group *jdb.FactoryGroup, cors map[int]*wraith.CorS, units map[int]*wraith.Unit) *wraith.FactoryGroup {
 	g := &wraith.FactoryGroup{
-		CorS:     cors[group.CorSId],
-		Id:       group.Id,
-		No:       group.No,
-		Product:  units[group.Product],
-		StageQty: [4]int{group.Stage1Qty, group.Stage2Qty, group.Stage3Qty, group.Stage4Qty},
+		CorS:      cors[group.CorSId],
+		Id:        group.Id,
+		No:        group.No,
+		Product:   units[group.Product],
+		StageQty:  [4]int{group.Stage1Qty, group.Stage2Qty, group.Stage3Qty, group.Stage4Qty},
+		IdleTurns: group.IdleTurns,
 	}
 	for _, u := range group.Units {
 		g.Units = append(g.Units, &wraith.InventoryUnit{
@@ -377,13 +401,14 @@ func jdbMineGroupToWraithMineGroup(group *jdb.MineGroup, cors map[int]*wraith.Co
 			ActiveQty: group.TotalQty,
 			StowedQty: 0,
 		},
-		StageQty: [4]int{group.Stage1Qty, group.Stage2Qty, group.Stage3Qty, group.Stage4Qty},
+		StageQty:  [4]int{group.Stage1Qty, group.Stage2Qty, group.Stage3Qty, group.Stage4Qty},
+		IdleTurns: group.IdleTurns,
 	}
 	return g
 }
 
-func jdbNationToWraithNation(nation *jdb.Nation, players map[int]*wraith.Player, planets map[int]*wraith.Planet) *wraith.Nation {
-	return &wraith.Nation{
+func jdbNationToWraithNation(nation *jdb.Nation, players map[int]*wraith.Player, planets map[int]*wraith.Planet, units map[int]*wraith.Unit) *wraith.Nation {
+	n := &wraith.Nation{
 		Id:                 nation.Id,
 		No:                 nation.No,
 		Name:               nation.Name,
@@ -394,7 +419,37 @@ func jdbNationToWraithNation(nation *jdb.Nation, players map[int]*wraith.Player,
 		Speciality:         nation.Speciality,
 		TechLevel:          nation.TechLevel,
 		ResearchPointsPool: nation.ResearchPointsPool,
+		Skills: wraith.Skills{
+			Biology:       nation.Skills.Biology,
+			Bureaucracy:   nation.Skills.Bureaucracy,
+			Gravitics:     nation.Skills.Gravitics,
+			LifeSupport:   nation.Skills.LifeSupport,
+			Manufacturing: nation.Skills.Manufacturing,
+			Military:      nation.Skills.Military,
+			Mining:        nation.Skills.Mining,
+			Shields:       nation.Skills.Shields,
+		},
+		Surveys: make(map[int]*wraith.Survey),
+	}
+	for _, survey := range nation.Surveys {
+		s := &wraith.Survey{
+			Planet:         planets[survey.PlanetId],
+			Year:           survey.Year,
+			Quarter:        survey.Quarter,
+			Kind:           survey.Kind,
+			HabitabilityNo: survey.HabitabilityNo,
+		}
+		for _, d := range survey.Deposits {
+			s.Deposits = append(s.Deposits, &wraith.SurveyDeposit{
+				No:           d.No,
+				Product:      units[d.UnitId],
+				YieldPct:     d.YieldPct,
+				RemainingQty: d.RemainingQty,
+			})
+		}
+		n.Surveys[survey.PlanetId] = s
 	}
+	return n
 }
 
 func jdbOrbitalColonyToWraithColony(colony *jdb.OrbitalColony, factoryGroup map[int]*wraith.FactoryGroup, farmGroup map[int]*wraith.FarmGroup, nations map[int]*wraith.Nation, planets map[int]*wraith.Planet, players map[int]*wraith.Player, units map[int]*wraith.Unit) *wraith.CorS {
@@ -418,6 +473,10 @@ func jdbOrbitalColonyToWraithColony(colony *jdb.OrbitalColony, factoryGroup map[
 			RebelPct:               colony.Population.RebelPct,
 			BirthsPriorTurn:        colony.Population.BirthsPriorTurn,
 			NaturalDeathsPriorTurn: colony.Population.NaturalDeathsPriorTurn,
+			ImmigrantsPriorTurn:    colony.Population.ImmigrantsPriorTurn,
+			EmigrantsPriorTurn:     colony.Population.EmigrantsPriorTurn,
+			StandardOfLiving:       colony.Population.StandardOfLiving,
+			Training:               jdbTraineesToWraithTrainees(colony.Population.Training),
 		},
 		Pay: wraith.Pay{
 			ProfessionalPct: colony.Pay.ProfessionalPct,
@@ -493,6 +552,10 @@ func jdbShipToWraithShip(ship *jdb.Ship, factoryGroup map[int]*wraith.FactoryGro
 			SpyTeamQty:             ship.Population.SpyTeamQty,
 			RebelPct:               ship.Population.RebelPct,
 			NaturalDeathsPriorTurn: ship.Population.NaturalDeathsPriorTurn,
+			ImmigrantsPriorTurn:    ship.Population.ImmigrantsPriorTurn,
+			EmigrantsPriorTurn:     ship.Population.EmigrantsPriorTurn,
+			StandardOfLiving:       ship.Population.StandardOfLiving,
+			Training:               jdbTraineesToWraithTrainees(ship.Population.Training),
 		},
 		Pay: wraith.Pay{
 			ProfessionalPct: ship.Pay.ProfessionalPct,
@@ -558,6 +621,10 @@ func jdbSurfaceColonyToWraithColony(colony *jdb.SurfaceColony, factoryGroup map[
 			RebelPct:               colony.Population.RebelPct,
 			BirthsPriorTurn:        colony.Population.BirthsPriorTurn,
 			NaturalDeathsPriorTurn: colony.Population.NaturalDeathsPriorTurn,
+			ImmigrantsPriorTurn:    colony.Population.ImmigrantsPriorTurn,
+			EmigrantsPriorTurn:     colony.Population.EmigrantsPriorTurn,
+			StandardOfLiving:       colony.Population.StandardOfLiving,
+			Training:               jdbTraineesToWraithTrainees(colony.Population.Training),
 		},
 		Pay: wraith.Pay{
 			ProfessionalPct: colony.Pay.ProfessionalPct,
@@ -625,20 +692,31 @@ func jdbSystemToWraithSystem(system *jdb.System) *wraith.System {
 	}
 }
 
+func jdbTraineesToWraithTrainees(trainees jdb.Trainees) (training []*wraith.Trainees) {
+	for _, t := range trainees {
+		training = append(training, &wraith.Trainees{Class: t.Class, Qty: t.Qty, TurnsLeft: t.TurnsLeft})
+	}
+	return training
+}
+
 func jdbUnitToWraithUnit(unit *jdb.Unit) *wraith.Unit {
 	return &wraith.Unit{
-		Id:                    unit.Id,
-		Kind:                  unit.Kind,
-		Code:                  unit.Code,
-		TechLevel:             unit.TechLevel,
-		Name:                  unit.Name,
-		Description:           unit.Description,
-		MassPerUnit:           unit.MassPerUnit,
-		VolumePerUnit:         unit.VolumePerUnit,
-		Hudnut:                unit.Hudnut,
-		StowedVolumePerUnit:   unit.StowedVolumePerUnit,
-		FuelPerUnitPerTurn:    unit.FuelPerUnitPerTurn,
-		MetsPerUnitPerTurn:    unit.MetsPerUnit,
-		NonMetsPerUnitPerTurn: unit.NonMetsPerUnit,
+		Id:                        unit.Id,
+		Kind:                      unit.Kind,
+		Code:                      unit.Code,
+		TechLevel:                 unit.TechLevel,
+		Name:                      unit.Name,
+		Description:               unit.Description,
+		MassPerUnit:               unit.MassPerUnit,
+		VolumePerUnit:             unit.VolumePerUnit,
+		Hudnut:                    unit.Hudnut,
+		StowedVolumePerUnit:       unit.StowedVolumePerUnit,
+		FuelPerUnitPerTurn:        unit.FuelPerUnitPerTurn,
+		FuelPerUnitPerCombatRound: unit.FuelPerUnitPerCombatRound,
+		MetsPerUnitPerTurn:        unit.MetsPerUnit,
+		NonMetsPerUnitPerTurn:     unit.NonMetsPerUnit,
+		UpkeepMetsPerUnitPerTurn:  unit.UpkeepMetsPerUnit,
+		UpkeepNmtsPerUnitPerTurn:  unit.UpkeepNmtsPerUnit,
+		UpkeepCngdPerUnitPerTurn:  unit.UpkeepCngdPerUnit,
 	}
 }
@@ -389,6 +389,45 @@ func (s *Server) logsGetHandler(templates string) http.HandlerFunc {
 	}
 }
 
+// ordersDiagnosticsPostHandler parses the orders in the request body and
+// returns the parser diagnostics as JSON. Nothing is saved.
+func (s *Server) ordersDiagnosticsPostHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, 64*1024) // enforce the same limit as the order entry form
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			log.Printf("%s: %s: %+v\n", r.Method, r.URL.Path, err)
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		} else if !utf8.Valid(b) {
+			log.Printf("%s: %s: invalid utf-8 string\n", r.Method, r.URL.Path)
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		p, err := orders.Parse(b)
+		if err != nil {
+			log.Printf("%s: %s: %+v\n", r.Method, r.URL.Path, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		var response struct {
+			Links struct {
+				Self string `json:"self"`
+			} `json:"links"`
+			Data []*orders.Diagnostic `json:"data"`
+		}
+		response.Links.Self = r.URL.Path
+		response.Data = orders.Diagnostics(p)
+		if response.Data == nil {
+			response.Data = []*orders.Diagnostic{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}
+}
+
 func (s *Server) ordersGetHandler(templates string) http.HandlerFunc {
 	t := osk.New(templates, "order_entry.html")
 
@@ -416,7 +455,7 @@ func (s *Server) ordersGetHandler(templates string) http.HandlerFunc {
 			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 			return
 		} else if claim.PlayerId == 0 {
-			log.Printf("%s: %s: player: claim.PlayerName %q: claim.PlayerId %d: playerId %q\n", r.Method, r.URL.Path, claim.PlayerName, claim.PlayerId)
+			log.Printf("%s: %s: player: claim.PlayerName %q: claim.PlayerId %d\n", r.Method, r.URL.Path, claim.PlayerName, claim.PlayerId)
 			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
 			return
 		}
@@ -460,6 +499,22 @@ func (s *Server) ordersGetHandler(templates string) http.HandlerFunc {
 				oe.Validate = fmt.Sprintf(";; sorry, but there was an error validating\n;; %+v\n", err)
 			} else {
 				bb := &bytes.Buffer{}
+				// check the orders against the state of the game for the turn
+				if jg, err := jdb.Load(filepath.Join(s.gamesPath, game.ShortName, oe.Year, oe.Quarter, "game.json")); err != nil {
+					log.Printf("%s: %s: %v\n", r.Method, r.URL.Path, err)
+					bb.WriteString(";; sorry, but the game state could not be loaded to validate against\n")
+				} else if e, err := adapters.JdbGameToWraithEngine(jg); err != nil {
+					log.Printf("%s: %s: %v\n", r.Method, r.URL.Path, err)
+					bb.WriteString(";; sorry, but the game state could not be loaded to validate against\n")
+				} else if player, ok := e.Players[claim.PlayerId]; !ok {
+					log.Printf("%s: %s: player %d: not in game\n", r.Method, r.URL.Path, claim.PlayerId)
+					bb.WriteString(";; sorry, but you are not a player in this game\n")
+				} else {
+					adapters.ValidateOrders(e, player, p...)
+				}
+				for _, d := range orders.Diagnostics(p) {
+					bb.WriteString(fmt.Sprintf(";; %d:%d: %s: %s: %s\n", d.Line, d.Col, d.Severity, d.Code, d.Message))
+				}
 				for _, order := range p {
 					bb.WriteString(order.String())
 					bb.Write([]byte{'\n'})
@@ -515,7 +570,7 @@ func (s *Server) ordersPostHandler() http.HandlerFunc {
 			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 			return
 		} else if claim.PlayerId == 0 {
-			log.Printf("%s: %s: player: claim.PlayerName %q: claim.PlayerId %d: playerId %d\n", r.Method, r.URL.Path, claim.PlayerName, claim.PlayerId)
+			log.Printf("%s: %s: player: claim.PlayerName %q: claim.PlayerId %d\n", r.Method, r.URL.Path, claim.PlayerName, claim.PlayerId)
 			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
 			return
 		}
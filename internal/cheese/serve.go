@@ -111,7 +111,7 @@ func (s *Server) serve() error {
 				_, claims, _ := jwtauth.FromContext(r.Context())
 				userId, ok := claims["user_id"].(string)
 				if !ok {
-					log.Printf("%s: %s: claims[%q] is not a string\n", "user_id")
+					log.Printf("%s: %s: claims[%q] is not a string\n", r.Method, r.URL.Path, "user_id")
 					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 					return
 				}
@@ -127,6 +127,7 @@ func (s *Server) serve() error {
 				_, _ = w.Write([]byte(fmt.Sprintf("claims.Player %q\n", claim.PlayerName)))
 				_, _ = w.Write([]byte("</pre></code></body>"))
 			})
+			r.Post("/orders/diagnostics", s.ordersDiagnosticsPostHandler())
 			r.Get("/panic", func(http.ResponseWriter, *http.Request) {
 				panic("panic")
 			})
@@ -0,0 +1,203 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package orders
+
+import (
+	"fmt"
+	"github.com/mdhender/wraith/internal/tokens"
+	"strings"
+	"unicode/utf8"
+)
+
+// Severity is how serious a diagnostic is.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // the order will not be executed
+	SeverityWarning Severity = "warning" // the order will be executed, but may not do what was intended
+)
+
+// diagnostic codes
+const (
+	CodeExpectedBlock        = "expected-block"         // a colony or ship id isn't followed by {
+	CodeExpectedToken        = "expected-token"         // a required argument is missing or has the wrong kind
+	CodeInvalidValue         = "invalid-value"          // an argument has the right kind but a bad value
+	CodeNestedBlock          = "nested-block"           // a block was opened inside another block
+	CodeUnclosedBlock        = "unclosed-block"         // a block is never closed
+	CodeUnexpectedBlockClose = "unexpected-block-close" // a } that doesn't close a block
+	CodeUnexpectedInput      = "unexpected-input"       // extra input at the end of an order
+	CodeUnknownOrder         = "unknown-order"          // the order verb isn't recognized
+)
+
+// diagnostic codes for orders that parse but don't fit the current state of the game
+const (
+	CodeNotCoLocated  = "not-co-located" // the target is not at the same location
+	CodeNotControlled = "not-controlled" // the colony, ship, or deposit is controlled by someone else
+	CodeNotFeasible   = "not-feasible"   // the colony or ship can't carry out the order
+	CodeNotFound      = "not-found"      // the colony, ship, unit, deposit, group, or location doesn't exist
+	CodeShortfall     = "shortfall"      // there isn't enough on hand to fill the whole order
+)
+
+// Diagnostic is a machine-readable description of a problem found by the parser or validator.
+// Columns start at 1. The span runs from Col up to, but not including, EndCol.
+// When Col and EndCol are the same, the span is the empty spot where input is missing.
+type Diagnostic struct {
+	Line     int      `json:"line"`
+	Col      int      `json:"col"`
+	EndCol   int      `json:"end_col"`
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	Message  string   `json:"message"`
+	Fixes    []*Fix   `json:"fixes,omitempty"`
+}
+
+// Fix is a suggested change that replaces the diagnostic's span with Text.
+type Fix struct {
+	Message string `json:"message"`
+	Text    string `json:"text"`
+}
+
+func (d *Diagnostic) Error() string {
+	return fmt.Sprintf("%d:%d: %s: %s", d.Line, d.Col, d.Code, d.Message)
+}
+
+// Diagnostics returns the diagnostics for all the orders, in order.
+func Diagnostics(orders []*Order) (list []*Diagnostic) {
+	for _, o := range orders {
+		list = append(list, o.Diagnostics...)
+	}
+	return list
+}
+
+// diagnose creates a diagnostic for each error found while parsing the order.
+// The first rejected token is the one the parser choked on, so it is used for the span.
+func diagnose(o *Order) (list []*Diagnostic) {
+	for _, err := range o.Errors {
+		msg := strings.TrimPrefix(err.Error(), fmt.Sprintf("%d: ", o.Line))
+		d := &Diagnostic{Line: o.Line, Severity: SeverityError, Message: msg}
+		d.Col, d.EndCol = spanOf(o.Verb)
+		switch {
+		case o.Verb != nil && o.Verb.Kind == tokens.BlockClose:
+			d.Code = CodeUnexpectedBlockClose
+			d.Fixes = append(d.Fixes, &Fix{Message: "remove the }"})
+		case strings.HasPrefix(msg, "expected {"):
+			d.Code = CodeExpectedBlock
+			d.Fixes = append(d.Fixes, &Fix{Message: "open a block", Text: o.Verb.String() + " {"})
+		case strings.HasPrefix(msg, "block for") && strings.HasSuffix(msg, "never closed"):
+			d.Code = CodeUnclosedBlock
+		case strings.HasPrefix(msg, "block for"), strings.HasPrefix(msg, "order is inside nested block"):
+			d.Code = CodeNestedBlock
+		case strings.HasPrefix(msg, "unknown order"):
+			d.Code = CodeUnknownOrder
+			for _, verb := range suggestVerbs(o.Verb.String()) {
+				d.Fixes = append(d.Fixes, &Fix{Message: fmt.Sprintf("did you mean %q?", verb), Text: verb})
+			}
+		case strings.HasPrefix(msg, "unexpected input"):
+			d.Code = CodeUnexpectedInput
+			if len(o.Reject) != 0 {
+				d.Col, _ = spanOf(o.Reject[0])
+				_, d.EndCol = spanOf(o.Reject[len(o.Reject)-1])
+			}
+			d.Fixes = append(d.Fixes, &Fix{Message: "remove the extra input"})
+		default:
+			if strings.HasPrefix(msg, "expected") {
+				d.Code = CodeExpectedToken
+			} else {
+				d.Code = CodeInvalidValue
+			}
+			if len(o.Reject) != 0 {
+				d.Col, d.EndCol = spanOf(o.Reject[0])
+			} else {
+				// the input ended early, so point just past the last argument
+				last := o.Verb
+				if len(o.Args) != 0 {
+					last = o.Args[len(o.Args)-1]
+				}
+				_, d.Col = spanOf(last)
+				d.EndCol = d.Col
+			}
+		}
+		list = append(list, d)
+	}
+	return list
+}
+
+// Span returns the columns covered by the order, from the start of the verb to the end of the last argument.
+func (o *Order) Span() (col, endCol int) {
+	col, endCol = spanOf(o.Verb)
+	for _, arg := range o.Args {
+		if _, end := spanOf(arg); end > endCol {
+			endCol = end
+		}
+	}
+	return col, endCol
+}
+
+// spanOf returns the columns covered by the token.
+func spanOf(t *tokens.Token) (col, endCol int) {
+	if t == nil {
+		return 0, 0
+	}
+	return t.Col, t.Col + utf8.RuneCount(t.Text)
+}
+
+// verbs are the words that start an order
+var verbs = []string{
+	"assemble", "attack", "build", "buy", "control", "counter-intel", "defend", "disassemble", "draft",
+	"gather-intel", "incite", "jump", "maintain", "move", "name", "offer", "pay", "raid", "ration",
+	"research", "retool", "sabotage", "sell", "setup", "survey", "transfer",
+}
+
+// suggestVerbs returns the verbs that are within two edits of the word.
+func suggestVerbs(word string) (list []string) {
+	word = strings.ToLower(word)
+	for _, verb := range verbs {
+		if editDistance(word, verb) <= 2 {
+			list = append(list, verb)
+		}
+	}
+	return list
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev, curr := make([]int, len(rb)+1), make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j] + 1
+			if n := curr[j-1] + 1; n < curr[j] {
+				curr[j] = n
+			}
+			if n := prev[j-1] + cost; n < curr[j] {
+				curr[j] = n
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -19,8 +19,11 @@
 package orders
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/mdhender/wraith/internal/tokens"
+	"math"
+	"sort"
 )
 
 type Arg struct {
@@ -32,11 +35,30 @@ type Arg struct {
 }
 
 type Order struct {
-	Line   int
-	Verb   *tokens.Token
-	Args   []*tokens.Token
-	Reject []*tokens.Token // nil unless there was an error parsing
-	Errors []error         // nil unless there was an error parsing
+	Line        int
+	Verb        *tokens.Token
+	Args        []*tokens.Token
+	Standing    bool            // true if the order repeats every turn
+	Priority    int             // 1 runs first; 0 if not given
+	Condition   *Condition      // nil unless the order only runs when the condition holds
+	Reject      []*tokens.Token // nil unless there was an error parsing
+	Errors      []error         // nil unless there was an error parsing
+	Diagnostics []*Diagnostic   // nil unless there was an error parsing or validating
+}
+
+// Condition compares the quantity of a unit in the inventory of the order's colony or ship to a number.
+// It is written in front of the order, as in "if fuel < 100000 then ...".
+type Condition struct {
+	Unit     *tokens.Token
+	Op       *tokens.Token // <, <=, =, ==, !=, >=, or >
+	Quantity *tokens.Token
+}
+
+func (c *Condition) String() string {
+	if c == nil {
+		return ""
+	}
+	return fmt.Sprintf("if %s %s %s then", c.Unit.String(), c.Op.String(), c.Quantity.String())
 }
 
 func (o *Order) String() string {
@@ -44,8 +66,24 @@ func (o *Order) String() string {
 	if o == nil {
 		return s
 	}
+	if o.Standing {
+		s += "every turn "
+	}
+	if o.Priority != 0 {
+		s += fmt.Sprintf("priority %d ", o.Priority)
+	}
+	if o.Condition != nil {
+		s += o.Condition.String() + " "
+	}
 	if o.Verb != nil {
 		s += string(o.Verb.Text)
+		// the kind of build is folded into the verb, so put it back for the order to parse again
+		switch o.Verb.Kind {
+		case tokens.BuildColony:
+			s += " colony"
+		case tokens.BuildShip:
+			s += " ship"
+		}
 	}
 	for _, t := range o.Args {
 		if t != nil {
@@ -120,6 +158,85 @@ func (o *Order) expectAssemble(z *tokens.Tokenizer) bool {
 	return true
 }
 
+func (o *Order) expectAttack(z *tokens.Tokenizer) bool {
+	var t *tokens.Token
+	if t = accept(z, tokens.ColonyId, tokens.ShipId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected ship or colony id", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.ColonyId, tokens.ShipId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected target ship or colony id", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.EOL, tokens.EOF); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: unexpected input on attack order", o.Line))
+		o.reject(z)
+		return false
+	}
+	return true
+}
+
+// expectBuild accepts "build ship CS qty unit" and "build colony CS location kind qty unit".
+func (o *Order) expectBuild(z *tokens.Tokenizer) bool {
+	var t *tokens.Token
+	if t = accept(z, tokens.Text); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected colony or ship", o.Line))
+		o.reject(z)
+		return false
+	} else if bytes.Equal(t.Text, []byte("colony")) {
+		o.Verb.Kind = tokens.BuildColony
+	} else if bytes.Equal(t.Text, []byte("ship")) {
+		o.Verb.Kind = tokens.BuildShip
+	} else {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected colony or ship, got %q", o.Line, t.String()))
+		o.reject(z)
+		return false
+	}
+	if t = accept(z, tokens.ColonyId, tokens.ShipId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected ship or colony id", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if o.Verb.Kind == tokens.BuildColony {
+		if t = accept(z, tokens.LocationId); t == nil || t.Location.OrbitNo == 0 {
+			o.Errors = append(o.Errors, fmt.Errorf("%d: expected orbit for the new colony", o.Line))
+			o.reject(z)
+			return false
+		}
+		o.Args = append(o.Args, t)
+		// the kind of colony is passed through as text and validated by the engine
+		if t = accept(z, tokens.Text); t == nil {
+			o.Errors = append(o.Errors, fmt.Errorf("%d: expected enclosed or surface", o.Line))
+			o.reject(z)
+			return false
+		}
+		o.Args = append(o.Args, t)
+	}
+	if t = accept(z, tokens.Integer); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected quantity", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.LightStructuralUnit, tokens.StructuralUnit, tokens.SuperLightStructuralUnit, tokens.UnitCode); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected structural unit to build with", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.EOL, tokens.EOF); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: unexpected input on build order", o.Line))
+		o.reject(z)
+		return false
+	}
+	return true
+}
+
 func (o *Order) expectCorSId(z *tokens.Tokenizer) bool {
 	var t *tokens.Token
 	if t = accept(z, tokens.ColonyId, tokens.ShipId); t == nil {
@@ -136,8 +253,111 @@ func (o *Order) expectCorSId(z *tokens.Tokenizer) bool {
 	return true
 }
 
+func (o *Order) expectDisassemble(z *tokens.Tokenizer) bool {
+	var t *tokens.Token
+	if t = accept(z, tokens.ColonyId, tokens.ShipId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected ship or colony id", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.Integer); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected quantity", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z,
+		tokens.AntiMissileUnit, tokens.AssaultCraftUnit, tokens.AssaultWeaponUnit, tokens.AutomationUnit,
+		tokens.EnergyShieldUnit, tokens.EnergyWeaponUnit, tokens.FactoryUnit, tokens.FarmUnit,
+		tokens.HyperDriveUnit, tokens.LifeSupportUnit, tokens.LightStructuralUnit,
+		tokens.MilitaryRobotUnit, tokens.MineUnit, tokens.MissileLauncherUnit,
+		tokens.PowerPlantUnit, tokens.ResearchUnit, tokens.SensorUnit, tokens.SolarArrayUnit, tokens.SpaceDriveUnit,
+		tokens.StructuralUnit, tokens.SuperLightStructuralUnit, tokens.TransportUnit,
+		tokens.UnitCode); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected unit to disassemble", o.Line))
+		o.reject(z)
+		return false
+	}
+	// unit codes are validated by the engine
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.EOL, tokens.EOF); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: unexpected input on disassemble order", o.Line))
+		o.reject(z)
+		return false
+	}
+	return true
+}
+
+func (o *Order) expectDraft(z *tokens.Tokenizer) bool {
+	var t *tokens.Token
+	if t = accept(z, tokens.ColonyId, tokens.ShipId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected ship or colony id", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.Integer); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected quantity", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.Professional, tokens.Soldier, tokens.Unskilled, tokens.ConstructionCrew); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected professional, soldier, unskilled, or construction-crew", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.EOL, tokens.EOF); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: unexpected input on draft order", o.Line))
+		o.reject(z)
+		return false
+	}
+	return true
+}
+
+func (o *Order) expectEspionage(z *tokens.Tokenizer) bool {
+	var t *tokens.Token
+	if t = accept(z, tokens.ColonyId, tokens.ShipId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected ship or colony id", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.Integer); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected number of spy teams", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if o.Verb.Kind != tokens.CounterIntel {
+		if t = accept(z, tokens.ColonyId, tokens.ShipId); t == nil {
+			o.Errors = append(o.Errors, fmt.Errorf("%d: expected target ship or colony id", o.Line))
+			o.reject(z)
+			return false
+		}
+		o.Args = append(o.Args, t)
+	}
+	if o.Verb.Kind == tokens.Sabotage {
+		if t = accept(z, tokens.FactoryGroupId); t == nil {
+			o.Errors = append(o.Errors, fmt.Errorf("%d: expected factory group id", o.Line))
+			o.reject(z)
+			return false
+		}
+		o.Args = append(o.Args, t)
+	}
+	if t = accept(z, tokens.EOL, tokens.EOF); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: unexpected input on %s order", o.Line, o.Verb.String()))
+		o.reject(z)
+		return false
+	}
+	return true
+}
+
 func (o *Order) expectFactoryGroup(z *tokens.Tokenizer) bool {
 	var t *tokens.Token
+	// unit codes are validated by the engine
 	if t = accept(z,
 		tokens.AntiMissileUnit, tokens.AssaultCraftUnit, tokens.AssaultWeaponUnit,
 		tokens.AutomationUnit, tokens.ConsumerGoodsUnit, tokens.ConstructionCrew,
@@ -145,8 +365,9 @@ func (o *Order) expectFactoryGroup(z *tokens.Tokenizer) bool {
 		tokens.FactoryUnit, tokens.FarmUnit,
 		tokens.HyperDriveUnit, tokens.LifeSupportUnit, tokens.LightStructuralUnit,
 		tokens.MilitaryRobotUnit, tokens.MilitarySuppliesUnit, tokens.MineUnit, tokens.MissileUnit, tokens.MissileLauncherUnit,
-		tokens.ResearchUnit, tokens.SensorUnit, tokens.SpaceDriveUnit,
-		tokens.StructuralUnit, tokens.SuperLightStructuralUnit, tokens.TransportUnit); t == nil {
+		tokens.PowerPlantUnit, tokens.ResearchUnit, tokens.SensorUnit, tokens.SolarArrayUnit, tokens.SpaceDriveUnit,
+		tokens.StructuralUnit, tokens.SuperLightStructuralUnit, tokens.TransportUnit,
+		tokens.UnitCode); t == nil {
 		o.Errors = append(o.Errors, fmt.Errorf("%d: expected unit to produce", o.Line))
 		o.reject(z)
 		return false
@@ -176,6 +397,56 @@ func (o *Order) expectFarmGroup(z *tokens.Tokenizer) bool {
 	return true
 }
 
+func (o *Order) expectJump(z *tokens.Tokenizer) bool {
+	var t *tokens.Token
+	if t = accept(z, tokens.ShipId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected ship id", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.LocationId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected location", o.Line))
+		o.reject(z)
+		return false
+	} else if t.Location.Star == "" || t.Location.OrbitNo == 0 {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: jump location must include star and orbit", o.Line))
+		o.Reject = append(o.Reject, t)
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.EOL, tokens.EOF); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: unexpected input on jump order", o.Line))
+		o.reject(z)
+		return false
+	}
+	return true
+}
+
+func (o *Order) expectMaintain(z *tokens.Tokenizer) bool {
+	var t *tokens.Token
+	if t = accept(z, tokens.ColonyId, tokens.ShipId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected ship or colony id", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = acceptUnit(z); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected unit to maintain", o.Line))
+		o.reject(z)
+		return false
+	}
+	// unit codes are validated by the engine
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.EOL, tokens.EOF); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: unexpected input on maintain order", o.Line))
+		o.reject(z)
+		return false
+	}
+	return true
+}
+
 func (o *Order) expectMineGroup(z *tokens.Tokenizer) bool {
 	var t *tokens.Token
 	if t = accept(z, tokens.DepositId); t == nil {
@@ -192,6 +463,38 @@ func (o *Order) expectMineGroup(z *tokens.Tokenizer) bool {
 	return true
 }
 
+func (o *Order) expectMove(z *tokens.Tokenizer) bool {
+	var t *tokens.Token
+	if t = accept(z, tokens.ShipId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected ship id", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.Integer, tokens.LocationId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected orbit or location", o.Line))
+		o.reject(z)
+		return false
+	} else if t.Kind == tokens.Integer && !(1 <= t.Integer && t.Integer <= 10) {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: orbit must be between 1 and 10", o.Line))
+		o.Reject = append(o.Reject, t)
+		o.reject(z)
+		return false
+	} else if t.Kind == tokens.LocationId && t.Location.OrbitNo == 0 {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: move location must include orbit", o.Line))
+		o.Reject = append(o.Reject, t)
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.EOL, tokens.EOF); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: unexpected input on move order", o.Line))
+		o.reject(z)
+		return false
+	}
+	return true
+}
+
 func (o *Order) expectName(z *tokens.Tokenizer) bool {
 	var t *tokens.Token
 	if t = accept(z, tokens.ColonyId, tokens.ShipId); t == nil {
@@ -214,6 +517,387 @@ func (o *Order) expectName(z *tokens.Tokenizer) bool {
 	return true
 }
 
+func (o *Order) expectPay(z *tokens.Tokenizer) bool {
+	var t *tokens.Token
+	if t = accept(z, tokens.ColonyId, tokens.ShipId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected ship or colony id", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.Professional, tokens.Soldier, tokens.Unskilled); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected professional, soldier, or unskilled (PRO, SLD, or USK)", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.Percentage); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected percentage", o.Line))
+		o.reject(z)
+		return false
+	} else if t.Number < 0 {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: percentage must not be negative", o.Line))
+		o.Reject = append(o.Reject, t)
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.EOL, tokens.EOF); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: unexpected input on pay order", o.Line))
+		o.reject(z)
+		return false
+	}
+	return true
+}
+
+func (o *Order) expectOffer(z *tokens.Tokenizer) bool {
+	var t *tokens.Token
+	if t = accept(z, tokens.ColonyId, tokens.ShipId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected ship or colony id", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.Integer); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected quantity", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = acceptUnit(z); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected unit to offer", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.ColonyId, tokens.ShipId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected trading partner ship or colony id", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.Integer); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected quantity wanted in exchange", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = acceptUnit(z); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected unit wanted in exchange", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.EOL, tokens.EOF); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: unexpected input on offer order", o.Line))
+		o.reject(z)
+		return false
+	}
+	return true
+}
+
+func (o *Order) expectRaid(z *tokens.Tokenizer) bool {
+	var t *tokens.Token
+	if t = accept(z, tokens.ColonyId, tokens.ShipId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected ship or colony id", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.ColonyId, tokens.ShipId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected target ship or colony id", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z,
+		tokens.ConsumerGoodsUnit, tokens.FoodUnit, tokens.FuelUnit, tokens.GoldUnit,
+		tokens.MetallicsUnit, tokens.MilitarySuppliesUnit, tokens.NonMetallicsUnit); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected cargo to raid", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.EOL, tokens.EOF); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: unexpected input on raid order", o.Line))
+		o.reject(z)
+		return false
+	}
+	return true
+}
+
+func (o *Order) expectRation(z *tokens.Tokenizer) bool {
+	var t *tokens.Token
+	if t = accept(z, tokens.ColonyId, tokens.ShipId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected ship or colony id", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.Professional, tokens.Soldier, tokens.Unskilled, tokens.Unemployed); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected professional, soldier, unskilled, or unemployed (PRO, SLD, USK, or UEM)", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.Percentage); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected percentage", o.Line))
+		o.reject(z)
+		return false
+	} else if t.Number < 0 {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: percentage must not be negative", o.Line))
+		o.Reject = append(o.Reject, t)
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.EOL, tokens.EOF); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: unexpected input on ration order", o.Line))
+		o.reject(z)
+		return false
+	}
+	return true
+}
+
+func (o *Order) expectResearch(z *tokens.Tokenizer) bool {
+	var t *tokens.Token
+	if t = accept(z, tokens.Text); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected tech-level or skill to research", o.Line))
+		o.reject(z)
+		return false
+	}
+	// research targets are passed through as text and validated by the engine
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.EOL, tokens.EOF); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: unexpected input on research order", o.Line))
+		o.reject(z)
+		return false
+	}
+	return true
+}
+
+func (o *Order) expectRetool(z *tokens.Tokenizer) bool {
+	var t *tokens.Token
+	if t = accept(z, tokens.ColonyId, tokens.ShipId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected ship or colony id", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.FactoryGroupId); t != nil {
+		o.Verb.Kind = tokens.RetoolFactoryGroup
+		o.Args = append(o.Args, t)
+		if t = accept(z,
+			tokens.AntiMissileUnit, tokens.AssaultCraftUnit, tokens.AssaultWeaponUnit,
+			tokens.AutomationUnit, tokens.ConsumerGoodsUnit, tokens.ConstructionCrew,
+			tokens.EnergyShieldUnit, tokens.EnergyWeaponUnit,
+			tokens.FactoryUnit, tokens.FarmUnit,
+			tokens.HyperDriveUnit, tokens.LifeSupportUnit, tokens.LightStructuralUnit,
+			tokens.MilitaryRobotUnit, tokens.MilitarySuppliesUnit, tokens.MineUnit, tokens.MissileUnit, tokens.MissileLauncherUnit,
+			tokens.PowerPlantUnit, tokens.ResearchUnit, tokens.SensorUnit, tokens.SolarArrayUnit, tokens.SpaceDriveUnit,
+			tokens.StructuralUnit, tokens.SuperLightStructuralUnit, tokens.TransportUnit,
+			tokens.UnitCode); t == nil {
+			o.Errors = append(o.Errors, fmt.Errorf("%d: expected unit to produce", o.Line))
+			o.reject(z)
+			return false
+		}
+		o.Args = append(o.Args, t)
+	} else if t = accept(z, tokens.MineGroupId); t != nil {
+		o.Verb.Kind = tokens.RetoolMineGroup
+		o.Args = append(o.Args, t)
+		if t = accept(z, tokens.DepositId); t == nil {
+			o.Errors = append(o.Errors, fmt.Errorf("%d: expected deposit id", o.Line))
+			o.reject(z)
+			return false
+		}
+		o.Args = append(o.Args, t)
+	} else {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected factory or mine group id", o.Line))
+		o.reject(z)
+		return false
+	}
+	if t = accept(z, tokens.EOL, tokens.EOF); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: unexpected input on retool order", o.Line))
+		o.reject(z)
+		return false
+	}
+	return true
+}
+
+func (o *Order) expectSetup(z *tokens.Tokenizer) bool {
+	var t *tokens.Token
+	if t = accept(z, tokens.ColonyId, tokens.ShipId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected ship or colony id", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.Integer); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected quantity", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z,
+		tokens.AntiMissileUnit, tokens.AssaultCraftUnit, tokens.AssaultWeaponUnit, tokens.AutomationUnit,
+		tokens.EnergyShieldUnit, tokens.EnergyWeaponUnit, tokens.FactoryUnit, tokens.FarmUnit,
+		tokens.HyperDriveUnit, tokens.LifeSupportUnit, tokens.LightStructuralUnit,
+		tokens.MilitaryRobotUnit, tokens.MineUnit, tokens.MissileLauncherUnit,
+		tokens.PowerPlantUnit, tokens.ResearchUnit, tokens.SensorUnit, tokens.SolarArrayUnit, tokens.SpaceDriveUnit,
+		tokens.StructuralUnit, tokens.SuperLightStructuralUnit, tokens.TransportUnit,
+		tokens.UnitCode); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected unit to set up", o.Line))
+		o.reject(z)
+		return false
+	}
+	// unit codes are validated by the engine
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.EOL, tokens.EOF); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: unexpected input on setup order", o.Line))
+		o.reject(z)
+		return false
+	}
+	return true
+}
+
+func (o *Order) expectSurvey(z *tokens.Tokenizer) bool {
+	var t *tokens.Token
+	if t = accept(z, tokens.ColonyId, tokens.ShipId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected ship or colony id", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.LocationId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected system, star, or orbit to survey", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.EOL, tokens.EOF); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: unexpected input on survey order", o.Line))
+		o.reject(z)
+		return false
+	}
+	return true
+}
+
+func (o *Order) expectTrade(z *tokens.Tokenizer) bool {
+	var t *tokens.Token
+	if t = accept(z, tokens.ColonyId, tokens.ShipId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected ship or colony id", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.Integer); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected quantity", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = acceptUnit(z); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected unit to %s", o.Line, o.Verb.String()))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.Integer); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected price in gold", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.EOL, tokens.EOF); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: unexpected input on %s order", o.Line, o.Verb.String()))
+		o.reject(z)
+		return false
+	}
+	return true
+}
+
+func (o *Order) expectTransfer(z *tokens.Tokenizer) bool {
+	var t *tokens.Token
+	if t = accept(z, tokens.ColonyId, tokens.ShipId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected ship or colony id", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.ColonyId, tokens.ShipId); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected destination ship or colony id", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z, tokens.Integer); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected quantity", o.Line))
+		o.reject(z)
+		return false
+	}
+	o.Args = append(o.Args, t)
+	if t = accept(z,
+		tokens.Professional, tokens.Soldier, tokens.Unemployed, tokens.Unskilled,
+		tokens.ConstructionCrew, tokens.SpyTeam); t != nil {
+		o.Args = append(o.Args, t)
+	} else if t = accept(z,
+		tokens.AntiMissileUnit, tokens.AssaultCraftUnit, tokens.AssaultWeaponUnit,
+		tokens.AutomationUnit, tokens.ConsumerGoodsUnit,
+		tokens.EnergyShieldUnit, tokens.EnergyWeaponUnit,
+		tokens.FactoryUnit, tokens.FarmUnit, tokens.FoodUnit, tokens.FuelUnit, tokens.GoldUnit,
+		tokens.HyperDriveUnit, tokens.LifeSupportUnit, tokens.LightStructuralUnit,
+		tokens.MetallicsUnit, tokens.MilitaryRobotUnit, tokens.MilitarySuppliesUnit, tokens.MineUnit, tokens.MissileUnit, tokens.MissileLauncherUnit,
+		tokens.NonMetallicsUnit, tokens.PowerPlantUnit, tokens.ResearchUnit, tokens.SensorUnit, tokens.SolarArrayUnit, tokens.SpaceDriveUnit,
+		tokens.StructuralUnit, tokens.SuperLightStructuralUnit, tokens.TransportUnit,
+		tokens.UnitCode); t != nil {
+		// unit codes are validated by the engine
+		o.Args = append(o.Args, t)
+	} else {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: expected unit or population to transfer", o.Line))
+		o.reject(z)
+		return false
+	}
+	if t = accept(z, tokens.EOL, tokens.EOF); t == nil {
+		o.Errors = append(o.Errors, fmt.Errorf("%d: unexpected input on transfer order", o.Line))
+		o.reject(z)
+		return false
+	}
+	return true
+}
+
+// acceptUnit accepts any unit that can be traded.
+// unit codes are validated by the engine.
+func acceptUnit(z *tokens.Tokenizer) *tokens.Token {
+	return accept(z,
+		tokens.AntiMissileUnit, tokens.AssaultCraftUnit, tokens.AssaultWeaponUnit,
+		tokens.AutomationUnit, tokens.ConsumerGoodsUnit,
+		tokens.EnergyShieldUnit, tokens.EnergyWeaponUnit,
+		tokens.FactoryUnit, tokens.FarmUnit, tokens.FoodUnit, tokens.FuelUnit, tokens.GoldUnit,
+		tokens.HyperDriveUnit, tokens.LifeSupportUnit, tokens.LightStructuralUnit,
+		tokens.MetallicsUnit, tokens.MilitaryRobotUnit, tokens.MilitarySuppliesUnit, tokens.MineUnit, tokens.MissileUnit, tokens.MissileLauncherUnit,
+		tokens.NonMetallicsUnit, tokens.PowerPlantUnit, tokens.ResearchUnit, tokens.SensorUnit, tokens.SolarArrayUnit, tokens.SpaceDriveUnit,
+		tokens.StructuralUnit, tokens.SuperLightStructuralUnit, tokens.TransportUnit,
+		tokens.UnitCode)
+}
+
+// Sequence returns a copy of the orders in the sequence they should run in.
+// Orders with a priority come first, lowest number first, followed by the orders without one.
+// Orders with the same priority stay in the sequence they were given in.
+func Sequence(o []*Order) []*Order {
+	rank := func(order *Order) int {
+		if order == nil || order.Priority == 0 {
+			return math.MaxInt
+		}
+		return order.Priority
+	}
+	list := make([]*Order, len(o))
+	copy(list, o)
+	sort.SliceStable(list, func(i, j int) bool {
+		return rank(list[i]) < rank(list[j])
+	})
+	return list
+}
+
 // consume until we find EOL or EOF token.
 // the slice of tokens returned will not include EOL or EOF
 func (o *Order) reject(z *tokens.Tokenizer) {
@@ -0,0 +1,138 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package orders
+
+import (
+	"testing"
+)
+
+func TestSequence(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		priority []int // priority of each order, in the order given
+		want     []int // indexes of the orders, in the sequence they run in
+	}{
+		{name: "empty"},
+		{name: "no priorities keeps the given order",
+			priority: []int{0, 0, 0},
+			want:     []int{0, 1, 2},
+		},
+		{name: "lowest priority first",
+			priority: []int{3, 1, 2},
+			want:     []int{1, 2, 0},
+		},
+		{name: "priorities before no priority",
+			priority: []int{0, 5, 0, 1},
+			want:     []int{3, 1, 0, 2},
+		},
+		{name: "same priority keeps the given order",
+			priority: []int{2, 1, 2, 1, 0, 2},
+			want:     []int{1, 3, 0, 2, 5, 4},
+		},
+	} {
+		var o []*Order
+		for i, priority := range tc.priority {
+			o = append(o, &Order{Line: i, Priority: priority})
+		}
+		got := Sequence(o)
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: want %d orders: got %d", tc.name, len(tc.want), len(got))
+			continue
+		}
+		for i, want := range tc.want {
+			if got[i] != o[want] {
+				t.Errorf("%s: %d: want order %d: got order %d", tc.name, i, want, got[i].Line)
+			}
+		}
+		// the orders given must not be changed
+		for i, order := range o {
+			if order.Line != i {
+				t.Errorf("%s: %d: sequence changed the orders given", tc.name, i)
+				break
+			}
+		}
+	}
+}
+
+// TestOrderStringParses checks that every order reads back the same after String.
+// Standing orders are saved with String and parsed again on the next turn.
+func TestOrderStringParses(t *testing.T) {
+	for _, input := range []string{
+		"assemble C1 5 construction-crew",
+		"assemble C1 10 factory-1 consumer-goods",
+		"assemble C1 10 farm-1 food",
+		"assemble C1 10 mine-1 DP1",
+		"assemble C1 5 spy-team",
+		"attack S1 C2",
+		"build colony C1 1/2/3A#4 enclosed 100 structural",
+		"build ship C1 100 structural",
+		"buy C1 100 fuel 2",
+		"control C1",
+		"counter-intel C1 2",
+		"defend C1",
+		"disassemble C1 10 factory-1",
+		"draft C1 100 soldier",
+		"gather-intel C1 2 C2",
+		"incite C1 2 C2",
+		"jump S1 1/2/3A#4",
+		"maintain C1 factory-1",
+		"move S1 4",
+		"name C1 \"Alpha\"",
+		"offer C1 100 fuel C2 50 food",
+		"pay C1 professional 110%",
+		"raid S1 C2 fuel",
+		"ration C1 unemployed 50%",
+		"research mining",
+		"retool C1 FG1 consumer-goods",
+		"retool C1 MG1 DP2",
+		"sabotage C1 2 C2 FG1",
+		"sell C1 100 fuel 2",
+		"setup C1 10 factory-1",
+		"survey S1 1/2/3",
+		"transfer C1 S1 100 fuel",
+		"transfer C1 S1 10 professional",
+		"every turn pay C1 professional 110%",
+		"every turn build colony C1 1/2/3A#4 enclosed 100 structural",
+		"every turn priority 2 if fuel < 100 then build ship C1 100 structural",
+	} {
+		first, err := Parse([]byte(input))
+		if err != nil {
+			t.Errorf("%q: parse: want nil: got %v", input, err)
+			continue
+		} else if len(first) != 1 || first[0].Errors != nil {
+			t.Errorf("%q: parse: want 1 order without errors: got %d %v", input, len(first), first)
+			continue
+		}
+		saved := first[0].String()
+		second, err := Parse([]byte(saved))
+		if err != nil {
+			t.Errorf("%q: parse %q: want nil: got %v", input, saved, err)
+			continue
+		} else if len(second) != 1 || second[0].Errors != nil {
+			t.Errorf("%q: parse %q: want 1 order without errors: got %d %v", input, saved, len(second), second)
+			continue
+		}
+		if first[0].Verb.Kind != second[0].Verb.Kind {
+			t.Errorf("%q: parse %q: want verb %d: got %d", input, saved, first[0].Verb.Kind, second[0].Verb.Kind)
+		}
+		if got := second[0].String(); saved != got {
+			t.Errorf("%q: want %q: got %q", input, saved, got)
+		}
+	}
+}
@@ -19,31 +19,193 @@
 package orders
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/mdhender/wraith/internal/tokens"
 )
 
 func Parse(b []byte) ([]*Order, error) {
 	var orders []*Order
+	var blocks []*tokens.Token // ids of the colony or ship blocks that are open
+	var starts []int           // index of the first order in each open block
+	var prefixes []*prefix     // standing and conditional clauses, applied once the orders are parsed
 
 	for z := tokens.FromBytes(b); !z.IsEof(); {
+		// standing and conditional clauses come in front of the order they apply to
+		if pfx, cmd := expectPrefix(z); cmd != nil {
+			orders = append(orders, cmd)
+			continue
+		} else if pfx != nil {
+			pfx.at = len(orders)
+			prefixes = append(prefixes, pfx)
+		}
+
+		// inside a block, the id of the enclosing colony or ship applies to every order
+		if len(blocks) != 0 {
+			if cmd := useBlockId(z, blocks[len(blocks)-1]); cmd != nil {
+				orders = append(orders, cmd)
+				continue
+			}
+		}
+
 		if verb := accept(z, tokens.EOL); verb != nil {
 			continue
+		} else if id := accept(z, tokens.ColonyId, tokens.ShipId); id != nil {
+			cmd := &Order{Line: id.Line, Verb: id}
+			if t := accept(z, tokens.BlockOpen); t == nil {
+				cmd.Errors = append(cmd.Errors, fmt.Errorf("%d: expected { after %s", id.Line, id.String()))
+				cmd.reject(z)
+				orders = append(orders, cmd)
+				continue
+			} else if len(blocks) != 0 {
+				// nested blocks are an error, but the block is still tracked so that the braces balance
+				cmd.Errors = append(cmd.Errors, fmt.Errorf("%d: block for %s can not be nested inside block for %s", id.Line, id.String(), blocks[len(blocks)-1].String()))
+				orders = append(orders, cmd)
+			}
+			blocks, starts = append(blocks, id), append(starts, len(orders))
+			continue
+		} else if verb = accept(z, tokens.BlockClose); verb != nil {
+			if len(blocks) == 0 {
+				cmd := &Order{Line: verb.Line, Verb: verb}
+				cmd.Errors = append(cmd.Errors, fmt.Errorf("%d: unexpected } outside of a block", verb.Line))
+				orders = append(orders, cmd)
+				continue
+			}
+			if len(blocks) > 1 {
+				nestedBlockErrors(orders[starts[len(starts)-1]:], blocks[len(blocks)-1])
+			}
+			blocks, starts = blocks[:len(blocks)-1], starts[:len(starts)-1]
+			continue
 		} else if verb = accept(z, tokens.Assemble); verb != nil {
 			cmd := &Order{Line: verb.Line, Verb: verb}
 			cmd.expectAssemble(z)
 			orders = append(orders, cmd)
 			continue
+		} else if verb = accept(z, tokens.Attack); verb != nil {
+			cmd := &Order{Line: verb.Line, Verb: verb}
+			cmd.expectAttack(z)
+			orders = append(orders, cmd)
+			continue
+		} else if verb = accept(z, tokens.Build); verb != nil {
+			cmd := &Order{Line: verb.Line, Verb: verb}
+			cmd.expectBuild(z)
+			orders = append(orders, cmd)
+			continue
+		} else if verb = accept(z, tokens.Buy); verb != nil {
+			cmd := &Order{Line: verb.Line, Verb: verb}
+			cmd.expectTrade(z)
+			orders = append(orders, cmd)
+			continue
 		} else if verb = accept(z, tokens.Control); verb != nil {
 			cmd := &Order{Line: verb.Line, Verb: verb}
 			cmd.expectCorSId(z)
 			orders = append(orders, cmd)
 			continue
+		} else if verb = accept(z, tokens.CounterIntel); verb != nil {
+			cmd := &Order{Line: verb.Line, Verb: verb}
+			cmd.expectEspionage(z)
+			orders = append(orders, cmd)
+			continue
+		} else if verb = accept(z, tokens.Defend); verb != nil {
+			cmd := &Order{Line: verb.Line, Verb: verb}
+			cmd.expectCorSId(z)
+			orders = append(orders, cmd)
+			continue
+		} else if verb = accept(z, tokens.Disassemble); verb != nil {
+			cmd := &Order{Line: verb.Line, Verb: verb}
+			cmd.expectDisassemble(z)
+			orders = append(orders, cmd)
+			continue
+		} else if verb = accept(z, tokens.Draft); verb != nil {
+			cmd := &Order{Line: verb.Line, Verb: verb}
+			cmd.expectDraft(z)
+			orders = append(orders, cmd)
+			continue
+		} else if verb = accept(z, tokens.GatherIntel); verb != nil {
+			cmd := &Order{Line: verb.Line, Verb: verb}
+			cmd.expectEspionage(z)
+			orders = append(orders, cmd)
+			continue
+		} else if verb = accept(z, tokens.Incite); verb != nil {
+			cmd := &Order{Line: verb.Line, Verb: verb}
+			cmd.expectEspionage(z)
+			orders = append(orders, cmd)
+			continue
+		} else if verb = accept(z, tokens.Jump); verb != nil {
+			cmd := &Order{Line: verb.Line, Verb: verb}
+			cmd.expectJump(z)
+			orders = append(orders, cmd)
+			continue
+		} else if verb = accept(z, tokens.Maintain); verb != nil {
+			cmd := &Order{Line: verb.Line, Verb: verb}
+			cmd.expectMaintain(z)
+			orders = append(orders, cmd)
+			continue
+		} else if verb = accept(z, tokens.Move); verb != nil {
+			cmd := &Order{Line: verb.Line, Verb: verb}
+			cmd.expectMove(z)
+			orders = append(orders, cmd)
+			continue
 		} else if verb = accept(z, tokens.Name); verb != nil {
 			cmd := &Order{Line: verb.Line, Verb: verb}
 			cmd.expectName(z)
 			orders = append(orders, cmd)
 			continue
+		} else if verb = accept(z, tokens.Offer); verb != nil {
+			cmd := &Order{Line: verb.Line, Verb: verb}
+			cmd.expectOffer(z)
+			orders = append(orders, cmd)
+			continue
+		} else if verb = accept(z, tokens.Pay); verb != nil {
+			cmd := &Order{Line: verb.Line, Verb: verb}
+			cmd.expectPay(z)
+			orders = append(orders, cmd)
+			continue
+		} else if verb = accept(z, tokens.Raid); verb != nil {
+			cmd := &Order{Line: verb.Line, Verb: verb}
+			cmd.expectRaid(z)
+			orders = append(orders, cmd)
+			continue
+		} else if verb = accept(z, tokens.Ration); verb != nil {
+			cmd := &Order{Line: verb.Line, Verb: verb}
+			cmd.expectRation(z)
+			orders = append(orders, cmd)
+			continue
+		} else if verb = accept(z, tokens.Research); verb != nil {
+			cmd := &Order{Line: verb.Line, Verb: verb}
+			cmd.expectResearch(z)
+			orders = append(orders, cmd)
+			continue
+		} else if verb = accept(z, tokens.Retool); verb != nil {
+			cmd := &Order{Line: verb.Line, Verb: verb}
+			cmd.expectRetool(z)
+			orders = append(orders, cmd)
+			continue
+		} else if verb = accept(z, tokens.Sabotage); verb != nil {
+			cmd := &Order{Line: verb.Line, Verb: verb}
+			cmd.expectEspionage(z)
+			orders = append(orders, cmd)
+			continue
+		} else if verb = accept(z, tokens.Sell); verb != nil {
+			cmd := &Order{Line: verb.Line, Verb: verb}
+			cmd.expectTrade(z)
+			orders = append(orders, cmd)
+			continue
+		} else if verb = accept(z, tokens.Setup); verb != nil {
+			cmd := &Order{Line: verb.Line, Verb: verb}
+			cmd.expectSetup(z)
+			orders = append(orders, cmd)
+			continue
+		} else if verb = accept(z, tokens.Survey); verb != nil {
+			cmd := &Order{Line: verb.Line, Verb: verb}
+			cmd.expectSurvey(z)
+			orders = append(orders, cmd)
+			continue
+		} else if verb = accept(z, tokens.Transfer); verb != nil {
+			cmd := &Order{Line: verb.Line, Verb: verb}
+			cmd.expectTransfer(z)
+			orders = append(orders, cmd)
+			continue
 		}
 
 		// unknown order. reject the entire line.
@@ -54,9 +216,192 @@ func Parse(b []byte) ([]*Order, error) {
 		orders = append(orders, cmd)
 	}
 
+	for _, pfx := range prefixes {
+		pfx.apply(orders[pfx.at])
+	}
+
+	for i := len(blocks) - 1; i >= 0; i-- {
+		if i > 0 {
+			nestedBlockErrors(orders[starts[i]:], blocks[i])
+		}
+		cmd := &Order{Line: blocks[i].Line, Verb: blocks[i]}
+		cmd.Errors = append(cmd.Errors, fmt.Errorf("%d: block for %s is never closed", blocks[i].Line, blocks[i].String()))
+		orders = append(orders, cmd)
+	}
+
+	for _, o := range orders {
+		o.Diagnostics = diagnose(o)
+	}
+
 	return orders, nil
 }
 
+// nestedBlockErrors flags the orders inside a nested block so that they are not executed.
+func nestedBlockErrors(orders []*Order, id *tokens.Token) {
+	for _, o := range orders {
+		if o.Errors == nil {
+			o.Errors = append(o.Errors, fmt.Errorf("%d: order is inside nested block for %s", o.Line, id.String()))
+		}
+	}
+}
+
+// prefix is the standing and conditional clauses in front of an order.
+type prefix struct {
+	at        int // index of the order the clauses apply to
+	standing  bool
+	priority  int
+	condition *Condition
+}
+
+// expectPrefix parses the optional "every turn", "priority n", and "if unit op quantity then"
+// clauses in front of an order. The clauses must be in that order.
+// It returns nil if there are no clauses.
+// If the clauses can't be parsed, it returns an order with the errors instead.
+// "every turn" on a line by itself is returned as an order that clears the standing orders.
+func expectPrefix(z *tokens.Tokenizer) (*prefix, *Order) {
+	var pfx *prefix
+	var last *tokens.Token // last keyword, used for error messages
+	if t := accept(z, tokens.Every); t != nil {
+		cmd := &Order{Line: t.Line, Verb: t}
+		if last = accept(z, tokens.Turn); last == nil {
+			cmd.Errors = append(cmd.Errors, fmt.Errorf("%d: expected turn after every", t.Line))
+			cmd.reject(z)
+			return nil, cmd
+		} else if next := z.Next(); next.Kind == tokens.EOL || next.Kind == tokens.EOF {
+			z.UnGet(next)
+			cmd.Args = append(cmd.Args, last)
+			return nil, cmd
+		} else {
+			z.UnGet(next)
+		}
+		pfx = &prefix{standing: true}
+	}
+	if t := accept(z, tokens.Priority); t != nil {
+		cmd := &Order{Line: t.Line, Verb: t}
+		if last = accept(z, tokens.Integer); last == nil || last.Integer < 1 {
+			cmd.Errors = append(cmd.Errors, fmt.Errorf("%d: expected priority of 1 or more", t.Line))
+			if last != nil {
+				z.UnGet(last)
+			}
+			cmd.reject(z)
+			return nil, cmd
+		}
+		if pfx == nil {
+			pfx = &prefix{}
+		}
+		pfx.priority = last.Integer
+	}
+	if t := accept(z, tokens.If); t != nil {
+		cmd := &Order{Line: t.Line, Verb: t}
+		c := &Condition{}
+		// unit codes are validated by the engine
+		if c.Unit = accept(z,
+			tokens.AntiMissileUnit, tokens.AssaultCraftUnit, tokens.AssaultWeaponUnit,
+			tokens.AutomationUnit, tokens.ConsumerGoodsUnit,
+			tokens.EnergyShieldUnit, tokens.EnergyWeaponUnit,
+			tokens.FactoryUnit, tokens.FarmUnit, tokens.FoodUnit, tokens.FuelUnit, tokens.GoldUnit,
+			tokens.HyperDriveUnit, tokens.LifeSupportUnit, tokens.LightStructuralUnit, tokens.MetallicsUnit,
+			tokens.MilitaryRobotUnit, tokens.MilitarySuppliesUnit, tokens.MineUnit, tokens.MissileUnit, tokens.MissileLauncherUnit,
+			tokens.NonMetallicsUnit, tokens.PowerPlantUnit, tokens.ResearchUnit, tokens.SensorUnit, tokens.SolarArrayUnit, tokens.SpaceDriveUnit,
+			tokens.StructuralUnit, tokens.SuperLightStructuralUnit, tokens.TransportUnit,
+			tokens.UnitCode); c.Unit == nil {
+			cmd.Errors = append(cmd.Errors, fmt.Errorf("%d: expected unit to test", t.Line))
+			cmd.reject(z)
+			return nil, cmd
+		}
+		cmd.Args = append(cmd.Args, c.Unit)
+		if c.Op = accept(z, tokens.Comparison); c.Op == nil {
+			cmd.Errors = append(cmd.Errors, fmt.Errorf("%d: expected comparison", t.Line))
+			cmd.reject(z)
+			return nil, cmd
+		}
+		cmd.Args = append(cmd.Args, c.Op)
+		if c.Quantity = accept(z, tokens.Integer); c.Quantity == nil {
+			cmd.Errors = append(cmd.Errors, fmt.Errorf("%d: expected quantity", t.Line))
+			cmd.reject(z)
+			return nil, cmd
+		}
+		cmd.Args = append(cmd.Args, c.Quantity)
+		if last = accept(z, tokens.Then); last == nil {
+			cmd.Errors = append(cmd.Errors, fmt.Errorf("%d: expected then", t.Line))
+			cmd.reject(z)
+			return nil, cmd
+		}
+		if pfx == nil {
+			pfx = &prefix{}
+		}
+		pfx.condition = c
+	}
+	if pfx == nil {
+		return nil, nil
+	}
+	// the clauses must be followed by an order on the same line
+	verb := z.Next()
+	z.UnGet(verb)
+	if !(tokens.Assemble <= verb.Kind && verb.Kind <= tokens.Transfer) {
+		cmd := &Order{Line: last.Line, Verb: last}
+		cmd.Errors = append(cmd.Errors, fmt.Errorf("%d: expected order after %s", last.Line, last.String()))
+		cmd.reject(z)
+		return nil, cmd
+	}
+	return pfx, nil
+}
+
+// apply copies the clauses to the order.
+// A condition tests the inventory of the order's colony or ship, so the order must have one.
+func (pfx *prefix) apply(o *Order) {
+	o.Standing, o.Priority, o.Condition = pfx.standing, pfx.priority, pfx.condition
+	if o.Condition == nil || o.Errors != nil {
+		return
+	}
+	for _, arg := range o.Args {
+		if arg.Kind == tokens.ColonyId || arg.Kind == tokens.ShipId {
+			return
+		}
+	}
+	o.Errors = append(o.Errors, fmt.Errorf("%d: condition needs an order for a colony or ship", o.Line))
+}
+
+// useBlockId pushes the id of the enclosing block back onto the input so that
+// the next order reads it as the id of the colony or ship it applies to.
+// The id goes after the verb, except for build orders, where it goes after
+// the kind of hull being built. Research orders don't take an id.
+// An order that gives the block's id itself is left alone. An order that gives
+// a different id is rejected and returned.
+func useBlockId(z *tokens.Tokenizer, id *tokens.Token) *Order {
+	verb := z.Next()
+	if !(tokens.Assemble <= verb.Kind && verb.Kind <= tokens.Transfer) || verb.Kind == tokens.Research {
+		z.UnGet(verb)
+		return nil
+	}
+	var kind *tokens.Token
+	if verb.Kind == tokens.Build {
+		if kind = accept(z, tokens.Text); kind == nil {
+			z.UnGet(verb)
+			return nil
+		}
+	}
+	if explicit := accept(z, tokens.ColonyId, tokens.ShipId); explicit == nil {
+		z.UnGet(&tokens.Token{Line: verb.Line, Col: verb.Col, Kind: id.Kind, Text: id.Text})
+	} else if bytes.Equal(explicit.Text, id.Text) {
+		z.UnGet(explicit)
+	} else {
+		cmd := &Order{Line: verb.Line, Verb: verb}
+		if kind != nil {
+			cmd.Args = append(cmd.Args, kind)
+		}
+		cmd.Errors = append(cmd.Errors, fmt.Errorf("%d: %s does not match the block for %s", verb.Line, explicit.String(), id.String()))
+		cmd.Reject = append(cmd.Reject, explicit)
+		cmd.reject(z)
+		return cmd
+	}
+	if kind != nil {
+		z.UnGet(kind)
+	}
+	z.UnGet(verb)
+	return nil
+}
+
 func accept(z *tokens.Tokenizer, kinds ...tokens.Kind) *tokens.Token {
 	tok := z.Next()
 	for _, kind := range kinds {
@@ -0,0 +1,247 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package orders
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		input string
+		want  []string // each order as returned by String, in order
+	}{
+		{name: "order",
+			input: "name C1 \"Alpha\"\n",
+			want:  []string{`name C1 "Alpha"`},
+		},
+		{name: "block",
+			input: "C1 {\n  name \"Alpha\"\n  pay professional 10%\n}\n",
+			want:  []string{`name C1 "Alpha"`, `pay C1 professional 10%`},
+		},
+		{name: "block on one line",
+			input: "C1 { name \"Alpha\" }\n",
+			want:  []string{`name C1 "Alpha"`},
+		},
+		{name: "block closed at end of input",
+			input: "C1 { name \"Alpha\" }",
+			want:  []string{`name C1 "Alpha"`},
+		},
+		{name: "block id follows kind of build",
+			input: "S2 {\n  build ship 100 structural\n  build colony 1/2/3A#4 surface 50 structural\n}\n",
+			want:  []string{`build ship S2 100 structural`, `build colony S2 1/2/3A#4 surface 50 structural`},
+		},
+		{name: "block id given again",
+			input: "C1 { assemble C1 5 construction-crew }\nS2 {\n  build ship S2 100 structural\n}\n",
+			want:  []string{`assemble C1 5 construction-crew`, `build ship S2 100 structural`},
+		},
+		{name: "block id conflicts",
+			input: "C1 {\n  assemble S2 5 construction-crew\n  build ship S2 100 structural\n}\n",
+			want: []string{
+				`assemble S2 5 construction-crew  ;; 2: S2 does not match the block for C1`,
+				`build ship S2 100 structural  ;; 3: S2 does not match the block for C1`,
+			},
+		},
+		{name: "block is not applied to research",
+			input: "C1 {\n  research mining\n}\n",
+			want:  []string{`research mining`},
+		},
+		{name: "block is not applied after the block",
+			input: "C1 {\n  name \"Alpha\"\n}\nname S2 \"Beta\"\n",
+			want:  []string{`name C1 "Alpha"`, `name S2 "Beta"`},
+		},
+		{name: "population class codes",
+			input: "pay C1 PRO 110%\npay C1 sld 100%\nration C1 USK 90%\nration C1 UEM 50%\n",
+			want:  []string{`pay C1 PRO 110%`, `pay C1 sld 100%`, `ration C1 USK 90%`, `ration C1 UEM 50%`},
+		},
+		{name: "unemployed are not paid",
+			input: "pay C1 UEM 50%\n",
+			want:  []string{`pay C1 UEM 50%  ;; 1: expected professional, soldier, or unskilled (PRO, SLD, or USK)`},
+		},
+		{name: "unit codes",
+			input: "assemble C1 10 factory-1 ESH-1\nretool C1 FG1 EWP-1\n",
+			want:  []string{`assemble C1 10 factory-1 esh-1`, `retool C1 FG1 ewp-1`},
+		},
+		{name: "product must be a unit",
+			input: "assemble C1 10 factory-1 widgets\nretool C1 FG1 widgets\n",
+			want: []string{
+				`assemble C1 10 factory-1 widgets  ;; 1: expected unit to produce`,
+				`retool C1 FG1 widgets  ;; 2: expected unit to produce`,
+			},
+		},
+		{name: "every turn",
+			input: "every turn pay C1 professional 10%\n",
+			want:  []string{`every turn pay C1 professional 10%`},
+		},
+		{name: "every turn by itself",
+			input: "every turn\n",
+			want:  []string{`every turn`},
+		},
+		{name: "priority",
+			input: "priority 2 name C1 \"Alpha\"\n",
+			want:  []string{`priority 2 name C1 "Alpha"`},
+		},
+		{name: "condition",
+			input: "if fuel < 100 then name C1 \"Alpha\"\n",
+			want:  []string{`if fuel < 100 then name C1 "Alpha"`},
+		},
+		{name: "all prefixes",
+			input: "every turn priority 3 if fuel >= 100 then pay C1 professional 10%\n",
+			want:  []string{`every turn priority 3 if fuel >= 100 then pay C1 professional 10%`},
+		},
+		{name: "prefixes in a block",
+			input: "C1 {\n  every turn priority 1 pay professional 10%\n}\n",
+			want:  []string{`every turn priority 1 pay C1 professional 10%`},
+		},
+		{name: "every without turn",
+			input: "every name C1 \"Alpha\"\n",
+			want:  []string{`every name C1 "Alpha"  ;; 1: expected turn after every`},
+		},
+		{name: "priority below one",
+			input: "priority 0 name C1 \"Alpha\"\n",
+			want:  []string{`priority 0 name C1 "Alpha"  ;; 1: expected priority of 1 or more`},
+		},
+		{name: "prefix without order",
+			input: "priority 2\n",
+			want:  []string{`2  ;; 1: expected order after 2`},
+		},
+		{name: "condition without colony or ship",
+			input: "if fuel < 100 then research mining\n",
+			want:  []string{`if fuel < 100 then research mining  ;; 1: condition needs an order for a colony or ship`},
+		},
+		{name: "unknown order",
+			input: "frobnicate C1\n",
+			want:  []string{`frobnicate C1  ;; unknown order "frobnicate"`},
+		},
+		{name: "unexpected block close",
+			input: "}\n",
+			want:  []string{`}  ;; 1: unexpected } outside of a block`},
+		},
+		{name: "unclosed block",
+			input: "C1 {\n  name \"Alpha\"\n",
+			want:  []string{`name C1 "Alpha"`, `C1  ;; 1: block for C1 is never closed`},
+		},
+		{name: "nested block",
+			input: "C1 {\n  S2 {\n    name \"Alpha\"\n  }\n}\n",
+			want: []string{
+				`S2  ;; 2: block for S2 can not be nested inside block for C1`,
+				`name S2 "Alpha"  ;; 3: order is inside nested block for S2`,
+			},
+		},
+	} {
+		o, err := Parse([]byte(tc.input))
+		if err != nil {
+			t.Errorf("%s: parse: want nil: got %v", tc.name, err)
+			continue
+		}
+		if len(o) != len(tc.want) {
+			var got []string
+			for _, order := range o {
+				got = append(got, order.String())
+			}
+			t.Errorf("%s: want %d orders: got %d %q", tc.name, len(tc.want), len(o), got)
+			continue
+		}
+		for i, want := range tc.want {
+			if got := o[i].String(); want != got {
+				t.Errorf("%s: order %d: want %q: got %q", tc.name, i+1, want, got)
+			}
+		}
+	}
+}
+
+func TestParsePrefix(t *testing.T) {
+	for _, tc := range []struct {
+		input     string
+		standing  bool
+		priority  int
+		condition string
+	}{
+		{input: "name C1 \"Alpha\"\n"},
+		{input: "every turn name C1 \"Alpha\"\n", standing: true},
+		{input: "priority 7 name C1 \"Alpha\"\n", priority: 7},
+		{input: "if FUEL != 0 then name C1 \"Alpha\"\n", condition: "if fuel != 0 then"},
+		{input: "every turn priority 2 if mss-1 > 10 then name C1 \"Alpha\"\n", standing: true, priority: 2, condition: "if mss-1 > 10 then"},
+	} {
+		o, err := Parse([]byte(tc.input))
+		if err != nil {
+			t.Errorf("%q: parse: want nil: got %v", tc.input, err)
+			continue
+		} else if len(o) != 1 {
+			t.Errorf("%q: want 1 order: got %d", tc.input, len(o))
+			continue
+		} else if o[0].Errors != nil {
+			t.Errorf("%q: want no errors: got %v", tc.input, o[0].Errors)
+			continue
+		}
+		if tc.standing != o[0].Standing {
+			t.Errorf("%q: standing: want %v: got %v", tc.input, tc.standing, o[0].Standing)
+		}
+		if tc.priority != o[0].Priority {
+			t.Errorf("%q: priority: want %d: got %d", tc.input, tc.priority, o[0].Priority)
+		}
+		if got := o[0].Condition.String(); tc.condition != got {
+			t.Errorf("%q: condition: want %q: got %q", tc.input, tc.condition, got)
+		}
+	}
+}
+
+func TestParseDiagnostics(t *testing.T) {
+	for _, tc := range []struct {
+		input  string
+		line   int
+		col    int
+		endCol int
+		code   string
+	}{
+		// the span covers the token that is wrong
+		{input: "frobnicate C1\n", line: 1, col: 1, endCol: 11, code: CodeUnknownOrder},
+		{input: "name C1 \"Alpha\" extra\n", line: 1, col: 17, endCol: 22, code: CodeUnexpectedInput},
+		{input: "every name C1 \"Alpha\"\n", line: 1, col: 7, endCol: 11, code: CodeExpectedToken},
+		{input: "priority 0 name C1 \"Alpha\"\n", line: 1, col: 10, endCol: 11, code: CodeExpectedToken},
+		// missing input is an empty span at the end of the order
+		{input: "name C1\n", line: 1, col: 8, endCol: 8, code: CodeExpectedToken},
+		// blocks
+		{input: "\n}\n", line: 2, col: 1, endCol: 2, code: CodeUnexpectedBlockClose},
+		{input: "C1 name \"Alpha\"\n", line: 1, col: 1, endCol: 3, code: CodeExpectedBlock},
+		{input: "C1 {\n", line: 1, col: 1, endCol: 3, code: CodeUnclosedBlock},
+		{input: "C1 {\n  S2 {\n  }\n}\n", line: 2, col: 3, endCol: 5, code: CodeNestedBlock},
+		{input: "C1 {\n  assemble S2 5 construction-crew\n}\n", line: 2, col: 12, endCol: 14, code: CodeInvalidValue},
+	} {
+		o, err := Parse([]byte(tc.input))
+		if err != nil {
+			t.Errorf("%q: parse: want nil: got %v", tc.input, err)
+			continue
+		}
+		d := Diagnostics(o)
+		if len(d) != 1 {
+			t.Errorf("%q: want 1 diagnostic: got %d", tc.input, len(d))
+			continue
+		}
+		if tc.line != d[0].Line || tc.col != d[0].Col || tc.endCol != d[0].EndCol {
+			t.Errorf("%q: span: want %d:%d-%d: got %d:%d-%d", tc.input, tc.line, tc.col, tc.endCol, d[0].Line, d[0].Col, d[0].EndCol)
+		}
+		if tc.code != d[0].Code {
+			t.Errorf("%q: code: want %q: got %q", tc.input, tc.code, d[0].Code)
+		} else if d[0].Severity != SeverityError {
+			t.Errorf("%q: severity: want %q: got %q", tc.input, SeverityError, d[0].Severity)
+		}
+	}
+}
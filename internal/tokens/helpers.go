@@ -18,6 +18,11 @@
 
 package tokens
 
+import (
+	"bytes"
+	"strconv"
+)
+
 // isId returns true if the word could be a valid id
 func isId(b []byte) bool {
 	// all ids are at least two characters
@@ -47,7 +52,7 @@ func isId(b []byte) bool {
 	if (b[0] == 'f' || b[0] == 'F') && (b[1] == 'g' || b[1] == 'G') && ('0' < b[2] && b[2] <= '9') {
 		return true
 	}
-	// mine group id is DP##
+	// mine group id is MG##
 	if (b[0] == 'm' || b[0] == 'M') && (b[1] == 'g' || b[1] == 'G') && ('0' < b[2] && b[2] <= '9') {
 		return true
 	}
@@ -56,6 +61,94 @@ func isId(b []byte) bool {
 	return false
 }
 
+// unitCodes are the codes for the units in the game (see models/bootstrap.go).
+var unitCodes = []string{
+	"ANM", "ASC", "ASW", "AUT", "CNGD", "ESH", "EWP", "FCT", "FOOD", "FRM",
+	"FUEL", "GOLD", "HDR", "LSP", "LTSU", "MIN", "MLR", "MLSP", "MSL", "MSS",
+	"MTLS", "NMTS", "PWP", "SDR", "SLR", "SLSU", "SNR", "STUN", "TPT",
+}
+
+// isUnitCode returns true if the word is a unit code, optionally followed by a tech level, like FUEL or ESH-1.
+// unit codes are not case-sensitive.
+func isUnitCode(b []byte) bool {
+	code := b
+	if i := bytes.IndexByte(b, '-'); i != -1 {
+		if _, ok := toInteger(b[i+1:]); !ok || bytes.ContainsAny(b[i+1:], ",_") {
+			return false
+		}
+		code = b[:i]
+	}
+	for _, u := range unitCodes {
+		if bytes.EqualFold(code, []byte(u)) {
+			return true
+		}
+	}
+	return false
+}
+
+// toLocation returns the location if the word is formatted as x/y/z,
+// optionally followed by a star sequence and an orbit number (x/y/zA#4).
+func toLocation(b []byte) (loc Location, ok bool) {
+	var coords [3]int
+	for n := 0; n < 3; n++ {
+		if n != 0 {
+			if len(b) == 0 || b[0] != '/' {
+				return Location{}, false
+			}
+			b = b[1:]
+		}
+		sign := 1
+		if len(b) != 0 && b[0] == '-' {
+			sign, b = -1, b[1:]
+		}
+		digits := 0
+		for ; len(b) != 0 && '0' <= b[0] && b[0] <= '9'; b, digits = b[1:], digits+1 {
+			coords[n] = coords[n]*10 + int(b[0]-'0')
+		}
+		if digits == 0 {
+			return Location{}, false
+		}
+		coords[n] = sign * coords[n]
+	}
+	loc.X, loc.Y, loc.Z = coords[0], coords[1], coords[2]
+	if len(b) == 0 {
+		return loc, true
+	}
+
+	// star sequence is a single letter
+	if !(('a' <= b[0] && b[0] <= 'z') || ('A' <= b[0] && b[0] <= 'Z')) {
+		return Location{}, false
+	}
+	loc.Star, b = string(bytes.ToUpper(b[:1])), b[1:]
+	if len(b) == 0 {
+		return loc, true
+	}
+
+	// orbit is #1 ... #10
+	if b[0] != '#' {
+		return Location{}, false
+	}
+	orbitNo, ok := toInteger(b[1:])
+	if !ok || !(1 <= orbitNo && orbitNo <= 10) {
+		return Location{}, false
+	}
+	loc.OrbitNo = orbitNo
+	return loc, true
+}
+
+// toPercentage returns the value if the word is a number followed by a percent sign.
+// the value is returned as written, so "110%" returns 110.
+func toPercentage(b []byte) (n float64, ok bool) {
+	if len(b) < 2 || b[len(b)-1] != '%' {
+		return 0, false
+	}
+	if i, ok := toInteger(b[:len(b)-1]); ok {
+		return float64(i), true
+	}
+	n, err := strconv.ParseFloat(string(b[:len(b)-1]), 64)
+	return n, err == nil
+}
+
 func toInteger(b []byte) (i int, ok bool) {
 	if len(b) == 0 {
 		return 0, false
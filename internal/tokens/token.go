@@ -20,11 +20,21 @@ package tokens
 
 // Token is a token from the input buffer
 type Token struct {
-	Kind    Kind
-	Line    int     // line number in the input
-	Integer int     // populated only for Integers
-	Number  float64 // populated for both number and percentage
-	Text    []byte  // always populated
+	Kind     Kind
+	Line     int      // line number in the input
+	Col      int      // column in the line, starting at 1
+	Integer  int      // populated only for Integers
+	Number   float64  // populated for both number and percentage
+	Location Location // populated only for LocationIds
+	Text     []byte   // always populated
+}
+
+// Location is an orbit in a star system, written as x/y/z, x/y/zA, or x/y/zA#orbit.
+// Star and OrbitNo are zero values when not given.
+type Location struct {
+	X, Y, Z int
+	Star    string // star sequence, A, B, etc
+	OrbitNo int    // 1...10
 }
 
 func (t *Token) String() string {
@@ -45,6 +55,7 @@ const (
 
 	// atoms, so to speak
 
+	Comparison
 	Integer
 	Number
 	Percentage
@@ -55,8 +66,19 @@ const (
 
 	ColonyId
 	DepositId
+	FactoryGroupId
+	LocationId
+	MineGroupId
 	ShipId
 
+	// keywords for standing, prioritized, and conditional orders
+
+	Every
+	If
+	Priority
+	Then
+	Turn
+
 	// order verbs
 
 	Assemble
@@ -65,8 +87,42 @@ const (
 	AssembleFarmGroup
 	AssembleMineGroup
 	AssembleSpyTeam
+	Attack
+	Build
+	BuildColony
+	BuildShip
+	Buy
 	Control
+	CounterIntel
+	Defend
+	Disassemble
+	Draft
+	GatherIntel
+	Incite
+	Jump
+	Maintain
+	Move
 	Name
+	Offer
+	Pay
+	Raid
+	Ration
+	Research
+	Retool
+	RetoolFactoryGroup
+	RetoolMineGroup
+	Sabotage
+	Sell
+	Setup
+	Survey
+	Transfer
+
+	// population classes
+
+	Professional
+	Soldier
+	Unemployed
+	Unskilled
 
 	// units
 
@@ -93,11 +149,14 @@ const (
 	MissileUnit
 	MissileLauncherUnit
 	NonMetallicsUnit
+	PowerPlantUnit
 	ResearchUnit
 	SensorUnit
+	SolarArrayUnit
 	SpaceDriveUnit
 	SpyTeam
 	StructuralUnit
 	SuperLightStructuralUnit
 	TransportUnit
+	UnitCode // code for a unit, like FUEL or ESH-1
 )
@@ -28,6 +28,8 @@ import (
 
 type Tokenizer struct {
 	line, offset int
+	bol          int // offset of the beginning of the current line
+	col          int // column of the last token scanned
 	buffer       []byte
 	pb           []*Token
 }
@@ -54,16 +56,31 @@ func (z *Tokenizer) Next() *Token {
 		z.pb = z.pb[:len(z.pb)-1]
 		return tok
 	}
+	tok := z.next()
+	tok.Col = z.col
+	return tok
+}
+
+// column returns the column, starting at 1, of the byte at the given offset in the current line.
+func (z *Tokenizer) column(offset int) int {
+	return utf8.RuneCount(z.buffer[z.bol:offset]) + 1
+}
 
+// next scans the next token from the input buffer.
+// It records the column the token starts in.
+func (z *Tokenizer) next() *Token {
 	var r rune
 	var w int
 
+	// the last rune of the input may start a token, so don't use IsEof to check for end of input
+	found := false
 	for !z.IsEof() {
 		r, w = utf8.DecodeRune(z.buffer[z.offset:])
 		z.offset += w
 
 		if r == '\n' {
-			z.line++
+			z.col = z.column(z.offset - w)
+			z.line, z.bol = z.line+1, z.offset
 			return &Token{Line: z.line - 1, Kind: EOL}
 		} else if r == ';' {
 			for !z.IsEof() {
@@ -75,18 +92,31 @@ func (z *Tokenizer) Next() *Token {
 		} else if unicode.IsControl(r) {
 			continue
 		} else if !unicode.IsSpace(r) {
+			found = true
 			break
 		}
 	}
 
-	if z.IsEof() {
+	if !found {
+		z.col = z.column(z.offset)
 		return &Token{Line: z.line, Kind: EOF}
 	}
+	z.col = z.column(z.offset - w)
 
 	if r == '{' {
 		return &Token{Line: z.line, Kind: BlockOpen, Text: z.buffer[z.offset-w : z.offset]}
 	} else if r == '}' {
-		return &Token{Line: z.line, Kind: BlockClose, Text: z.buffer[z.offset-w : z.offset]}
+		// a block close also ends any order in front of it on the same line,
+		// so return an end of line first and save the block close for the next call.
+		z.pb = append(z.pb, &Token{Line: z.line, Col: z.col, Kind: BlockClose, Text: z.buffer[z.offset-w : z.offset]})
+		return &Token{Line: z.line, Kind: EOL}
+	} else if r == '<' || r == '>' || r == '=' || r == '!' {
+		// comparisons are <, <=, =, ==, !=, >=, and >
+		start := z.offset - w
+		if !z.IsEof() && z.buffer[z.offset] == '=' {
+			z.offset++
+		}
+		return &Token{Line: z.line, Kind: Comparison, Text: z.buffer[start:z.offset]}
 	}
 
 	var word []byte
@@ -115,7 +145,7 @@ func (z *Tokenizer) Next() *Token {
 	for !z.IsEof() {
 		if r, w = utf8.DecodeRune(z.buffer[z.offset:]); r == '\n' {
 			break
-		} else if !(r == '-' || r == ',' || r == '.' || r == '%' || unicode.IsLetter(r) || unicode.IsDigit(r)) {
+		} else if !(r == '-' || r == ',' || r == '.' || r == '%' || r == '/' || r == '#' || unicode.IsLetter(r) || unicode.IsDigit(r)) {
 			break
 		}
 		z.offset += w
@@ -129,16 +159,28 @@ func (z *Tokenizer) Next() *Token {
 			return &Token{Line: z.line, Kind: ColonyId, Text: word}
 		case 'D':
 			return &Token{Line: z.line, Kind: DepositId, Text: word}
+		case 'F':
+			return &Token{Line: z.line, Kind: FactoryGroupId, Text: word}
+		case 'M':
+			return &Token{Line: z.line, Kind: MineGroupId, Text: word}
 		case 'S':
 			return &Token{Line: z.line, Kind: ShipId, Text: word}
 		}
 		panic(fmt.Sprintf("assert(word != %q)", string(word)))
 	}
 
+	if loc, ok := toLocation(word); ok {
+		return &Token{Line: z.line, Kind: LocationId, Text: bytes.ToUpper(word), Location: loc}
+	}
+
 	if i, ok := toInteger(word); ok {
 		return &Token{Line: z.line, Kind: Integer, Text: word, Integer: i}
 	}
 
+	if n, ok := toPercentage(word); ok {
+		return &Token{Line: z.line, Kind: Percentage, Text: word, Number: n}
+	}
+
 	if n, err := strconv.ParseFloat(string(word), 64); err == nil {
 		return &Token{Line: z.line, Kind: Number, Text: word, Number: n}
 	}
@@ -155,9 +197,18 @@ func (z *Tokenizer) Next() *Token {
 	if bytes.HasPrefix(word, []byte("assault-weapon-")) {
 		return &Token{Line: z.line, Kind: AssaultWeaponUnit, Text: word}
 	}
+	if bytes.Equal(word, []byte("attack")) {
+		return &Token{Line: z.line, Kind: Attack, Text: word}
+	}
 	if bytes.HasPrefix(word, []byte("automation-")) {
 		return &Token{Line: z.line, Kind: AutomationUnit, Text: word}
 	}
+	if bytes.Equal(word, []byte("build")) {
+		return &Token{Line: z.line, Kind: Build, Text: word}
+	}
+	if bytes.Equal(word, []byte("buy")) {
+		return &Token{Line: z.line, Kind: Buy, Text: word}
+	}
 	if bytes.Equal(word, []byte("consumer-goods")) {
 		return &Token{Line: z.line, Kind: ConsumerGoodsUnit, Text: word}
 	}
@@ -167,12 +218,27 @@ func (z *Tokenizer) Next() *Token {
 	if bytes.Equal(word, []byte("control")) {
 		return &Token{Line: z.line, Kind: Control, Text: word}
 	}
+	if bytes.Equal(word, []byte("counter-intel")) {
+		return &Token{Line: z.line, Kind: CounterIntel, Text: word}
+	}
+	if bytes.Equal(word, []byte("defend")) {
+		return &Token{Line: z.line, Kind: Defend, Text: word}
+	}
+	if bytes.Equal(word, []byte("disassemble")) {
+		return &Token{Line: z.line, Kind: Disassemble, Text: word}
+	}
+	if bytes.Equal(word, []byte("draft")) {
+		return &Token{Line: z.line, Kind: Draft, Text: word}
+	}
 	if bytes.HasPrefix(word, []byte("energy-shield-")) {
 		return &Token{Line: z.line, Kind: EnergyShieldUnit, Text: word}
 	}
 	if bytes.HasPrefix(word, []byte("energy-weapon-")) {
 		return &Token{Line: z.line, Kind: EnergyWeaponUnit, Text: word}
 	}
+	if bytes.Equal(word, []byte("every")) {
+		return &Token{Line: z.line, Kind: Every, Text: word}
+	}
 	if bytes.HasPrefix(word, []byte("factory-")) {
 		return &Token{Line: z.line, Kind: FactoryUnit, Text: word}
 	}
@@ -185,18 +251,33 @@ func (z *Tokenizer) Next() *Token {
 	if bytes.Equal(word, []byte("fuel")) {
 		return &Token{Line: z.line, Kind: FuelUnit, Text: word}
 	}
+	if bytes.Equal(word, []byte("gather-intel")) {
+		return &Token{Line: z.line, Kind: GatherIntel, Text: word}
+	}
 	if bytes.Equal(word, []byte("gold")) {
 		return &Token{Line: z.line, Kind: GoldUnit, Text: word}
 	}
 	if bytes.HasPrefix(word, []byte("hyper-drive-")) {
 		return &Token{Line: z.line, Kind: HyperDriveUnit, Text: word}
 	}
+	if bytes.Equal(word, []byte("if")) {
+		return &Token{Line: z.line, Kind: If, Text: word}
+	}
+	if bytes.Equal(word, []byte("incite")) {
+		return &Token{Line: z.line, Kind: Incite, Text: word}
+	}
+	if bytes.Equal(word, []byte("jump")) {
+		return &Token{Line: z.line, Kind: Jump, Text: word}
+	}
 	if bytes.HasPrefix(word, []byte("life-support-")) {
 		return &Token{Line: z.line, Kind: LifeSupportUnit, Text: word}
 	}
 	if bytes.Equal(word, []byte("light-structural")) {
 		return &Token{Line: z.line, Kind: LightStructuralUnit, Text: word}
 	}
+	if bytes.Equal(word, []byte("maintain")) {
+		return &Token{Line: z.line, Kind: Maintain, Text: word}
+	}
 	if bytes.Equal(word, []byte("metallics")) {
 		return &Token{Line: z.line, Kind: MetallicsUnit, Text: word}
 	}
@@ -209,24 +290,69 @@ func (z *Tokenizer) Next() *Token {
 	if bytes.HasPrefix(word, []byte("mine-")) {
 		return &Token{Line: z.line, Kind: MineUnit, Text: word}
 	}
-	if bytes.HasPrefix(word, []byte("missile-")) {
-		return &Token{Line: z.line, Kind: MissileUnit, Text: word}
+	if bytes.Equal(word, []byte("move")) {
+		return &Token{Line: z.line, Kind: Move, Text: word}
 	}
 	if bytes.HasPrefix(word, []byte("missile-launcher-")) {
 		return &Token{Line: z.line, Kind: MissileLauncherUnit, Text: word}
 	}
+	if bytes.HasPrefix(word, []byte("missile-")) {
+		return &Token{Line: z.line, Kind: MissileUnit, Text: word}
+	}
 	if bytes.Equal(word, []byte("name")) {
 		return &Token{Line: z.line, Kind: Name, Text: word}
 	}
 	if bytes.Equal(word, []byte("non-metallics")) {
 		return &Token{Line: z.line, Kind: NonMetallicsUnit, Text: word}
 	}
+	if bytes.Equal(word, []byte("offer")) {
+		return &Token{Line: z.line, Kind: Offer, Text: word}
+	}
+	if bytes.Equal(word, []byte("pay")) {
+		return &Token{Line: z.line, Kind: Pay, Text: word}
+	}
+	if bytes.HasPrefix(word, []byte("power-plant-")) {
+		return &Token{Line: z.line, Kind: PowerPlantUnit, Text: word}
+	}
+	if bytes.Equal(word, []byte("priority")) {
+		return &Token{Line: z.line, Kind: Priority, Text: word}
+	}
+	if bytes.Equal(word, []byte("professional")) || bytes.EqualFold(word, []byte("PRO")) {
+		return &Token{Line: z.line, Kind: Professional, Text: word}
+	}
+	if bytes.Equal(word, []byte("raid")) {
+		return &Token{Line: z.line, Kind: Raid, Text: word}
+	}
+	if bytes.Equal(word, []byte("ration")) {
+		return &Token{Line: z.line, Kind: Ration, Text: word}
+	}
+	if bytes.Equal(word, []byte("research")) {
+		return &Token{Line: z.line, Kind: Research, Text: word}
+	}
 	if bytes.HasPrefix(word, []byte("research-")) {
 		return &Token{Line: z.line, Kind: ResearchUnit, Text: word}
 	}
+	if bytes.Equal(word, []byte("retool")) {
+		return &Token{Line: z.line, Kind: Retool, Text: word}
+	}
+	if bytes.Equal(word, []byte("sabotage")) {
+		return &Token{Line: z.line, Kind: Sabotage, Text: word}
+	}
+	if bytes.Equal(word, []byte("sell")) {
+		return &Token{Line: z.line, Kind: Sell, Text: word}
+	}
 	if bytes.HasPrefix(word, []byte("sensor-")) {
 		return &Token{Line: z.line, Kind: SensorUnit, Text: word}
 	}
+	if bytes.Equal(word, []byte("setup")) {
+		return &Token{Line: z.line, Kind: Setup, Text: word}
+	}
+	if bytes.Equal(word, []byte("soldier")) || bytes.EqualFold(word, []byte("SLD")) {
+		return &Token{Line: z.line, Kind: Soldier, Text: word}
+	}
+	if bytes.HasPrefix(word, []byte("solar-array-")) {
+		return &Token{Line: z.line, Kind: SolarArrayUnit, Text: word}
+	}
 	if bytes.HasPrefix(word, []byte("space-drive-")) {
 		return &Token{Line: z.line, Kind: SpaceDriveUnit, Text: word}
 	}
@@ -239,9 +365,31 @@ func (z *Tokenizer) Next() *Token {
 	if bytes.Equal(word, []byte("super-light-structural")) {
 		return &Token{Line: z.line, Kind: SuperLightStructuralUnit, Text: word}
 	}
+	if bytes.Equal(word, []byte("survey")) {
+		return &Token{Line: z.line, Kind: Survey, Text: word}
+	}
+	if bytes.Equal(word, []byte("then")) {
+		return &Token{Line: z.line, Kind: Then, Text: word}
+	}
+	if bytes.Equal(word, []byte("transfer")) {
+		return &Token{Line: z.line, Kind: Transfer, Text: word}
+	}
 	if bytes.HasPrefix(word, []byte("transport-")) {
 		return &Token{Line: z.line, Kind: TransportUnit, Text: word}
 	}
+	if bytes.Equal(word, []byte("turn")) {
+		return &Token{Line: z.line, Kind: Turn, Text: word}
+	}
+	if bytes.Equal(word, []byte("unemployed")) || bytes.EqualFold(word, []byte("UEM")) {
+		return &Token{Line: z.line, Kind: Unemployed, Text: word}
+	}
+	if bytes.Equal(word, []byte("unskilled")) || bytes.EqualFold(word, []byte("USK")) {
+		return &Token{Line: z.line, Kind: Unskilled, Text: word}
+	}
+
+	if isUnitCode(word) {
+		return &Token{Line: z.line, Kind: UnitCode, Text: bytes.ToLower(word)}
+	}
 
 	return &Token{Line: z.line, Kind: Text, Text: bytes.ToLower(word)}
 }
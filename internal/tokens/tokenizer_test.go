@@ -0,0 +1,102 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package tokens
+
+import (
+	"testing"
+)
+
+func TestBlockClose(t *testing.T) {
+	type token struct {
+		kind Kind
+		text string
+		col  int
+	}
+	for _, tc := range []struct {
+		input string
+		want  []token
+	}{
+		// a block close ends the order in front of it, so an end of line comes first
+		{input: `name C1 "x" }`, want: []token{
+			{kind: Name, text: "name", col: 1},
+			{kind: ColonyId, text: "C1", col: 6},
+			{kind: QuotedText, text: `"x"`, col: 9},
+			{kind: EOL},
+			{kind: BlockClose, text: "}", col: 13},
+			{kind: EOF},
+		}},
+		{input: "}\n", want: []token{
+			{kind: EOL},
+			{kind: BlockClose, text: "}", col: 1},
+			{kind: EOL},
+			{kind: EOF},
+		}},
+		{input: "C1 { }", want: []token{
+			{kind: ColonyId, text: "C1", col: 1},
+			{kind: BlockOpen, text: "{", col: 4},
+			{kind: EOL},
+			{kind: BlockClose, text: "}", col: 6},
+			{kind: EOF},
+		}},
+	} {
+		z := FromString(tc.input)
+		for i, want := range tc.want {
+			got := z.Next()
+			if want.kind != got.Kind {
+				t.Errorf("%q: %d: kind: want %d: got %d", tc.input, i, want.kind, got.Kind)
+				break
+			}
+			if want.kind == EOL || want.kind == EOF {
+				continue
+			}
+			if want.text != string(got.Text) {
+				t.Errorf("%q: %d: text: want %q: got %q", tc.input, i, want.text, string(got.Text))
+			}
+			if want.col != got.Col {
+				t.Errorf("%q: %d: col: want %d: got %d", tc.input, i, want.col, got.Col)
+			}
+		}
+	}
+}
+
+func TestUnitCode(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		kind  Kind
+		text  string
+	}{
+		{input: "ESH-1", kind: UnitCode, text: "esh-1"},
+		{input: "ewp-12", kind: UnitCode, text: "ewp-12"},
+		{input: "FUEL", kind: UnitCode, text: "fuel"},
+		{input: "fuel", kind: FuelUnit, text: "fuel"},
+		{input: "energy-shield-1", kind: EnergyShieldUnit, text: "energy-shield-1"},
+		{input: "ESH-", kind: Text, text: "esh-"},
+		{input: "ESH-1,000", kind: Text, text: "esh-1,000"},
+		{input: "XYZ-1", kind: Text, text: "xyz-1"},
+		{input: "widgets", kind: Text, text: "widgets"},
+	} {
+		got := FromString(tc.input).Next()
+		if tc.kind != got.Kind {
+			t.Errorf("%q: kind: want %d: got %d", tc.input, tc.kind, got.Kind)
+		}
+		if tc.text != string(got.Text) {
+			t.Errorf("%q: text: want %q: got %q", tc.input, tc.text, string(got.Text))
+		}
+	}
+}
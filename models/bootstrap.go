@@ -81,8 +81,10 @@ func Bootstrap(cfg *config.Global) (*Store, error) {
 		{"MSL", "missile-launcher", "missile-launcher"},
 		{"MTLS", "metallics", "metallics"},
 		{"NMTS", "non-metallics", "non-metallics"},
+		{"PWP", "power-plant", "power-plant"},
 		{"SDR", "space-drive", "space-drive"},
 		{"SNR", "sensor", "sensor"},
+		{"SLR", "solar-array", "solar-array"},
 		{"SLSU", "super-light-structural", "super-light-structural"},
 		{"STUN", "structural", "structural"},
 		{"TPT", "transport", "transport"},
@@ -164,6 +164,16 @@ insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit
 insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('MSL-10', 10, 'missile-launcher-10', 'missile-launcher', 250, 250, 'Y', 250/2);
 insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('MTLS', 1, 'metallics', 'metallics', 1, 1, 'N', 1);
 insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('NMTS', 1, 'non-metallics', 'non-metallics', 1, 1, 'N', 1);
+insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('PWP-1', 1, 'power-plant-1', 'power-plant', 6, 6, 'Y', 6/2);
+insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('PWP-2', 2, 'power-plant-2', 'power-plant', 12, 12, 'Y', 12/2);
+insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('PWP-3', 3, 'power-plant-3', 'power-plant', 18, 18, 'Y', 18/2);
+insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('PWP-4', 4, 'power-plant-4', 'power-plant', 24, 24, 'Y', 24/2);
+insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('PWP-5', 5, 'power-plant-5', 'power-plant', 30, 30, 'Y', 30/2);
+insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('PWP-6', 6, 'power-plant-6', 'power-plant', 36, 36, 'Y', 36/2);
+insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('PWP-7', 7, 'power-plant-7', 'power-plant', 42, 42, 'Y', 42/2);
+insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('PWP-8', 8, 'power-plant-8', 'power-plant', 48, 48, 'Y', 48/2);
+insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('PWP-9', 9, 'power-plant-9', 'power-plant', 54, 54, 'Y', 54/2);
+insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('PWP-10', 10, 'power-plant-10', 'power-plant', 60, 60, 'Y', 60/2);
 insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('SDR-1', 1, 'space-drive-1', 'space-drive', 25, 25, 'Y', 25/2);
 insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('SDR-2', 2, 'space-drive-2', 'space-drive', 50, 50, 'Y', 50/2);
 insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('SDR-3', 3, 'space-drive-3', 'space-drive', 75, 75, 'Y', 75/2);
@@ -184,6 +194,16 @@ insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit
 insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('SNR-8', 8, 'sensor-8', 'sensor', 320, 320, 'Y', 320/2);
 insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('SNR-9', 9, 'sensor-9', 'sensor', 360, 360, 'Y', 360/2);
 insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('SNR-10', 10, 'sensor-10', 'sensor', 400, 400, 'Y', 400/2);
+insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('SLR-1', 1, 'solar-array-1', 'solar-array', 4, 4, 'Y', 4/2);
+insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('SLR-2', 2, 'solar-array-2', 'solar-array', 8, 8, 'Y', 8/2);
+insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('SLR-3', 3, 'solar-array-3', 'solar-array', 12, 12, 'Y', 12/2);
+insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('SLR-4', 4, 'solar-array-4', 'solar-array', 16, 16, 'Y', 16/2);
+insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('SLR-5', 5, 'solar-array-5', 'solar-array', 20, 20, 'Y', 20/2);
+insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('SLR-6', 6, 'solar-array-6', 'solar-array', 24, 24, 'Y', 24/2);
+insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('SLR-7', 7, 'solar-array-7', 'solar-array', 28, 28, 'Y', 28/2);
+insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('SLR-8', 8, 'solar-array-8', 'solar-array', 32, 32, 'Y', 32/2);
+insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('SLR-9', 9, 'solar-array-9', 'solar-array', 36, 36, 'Y', 36/2);
+insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('SLR-10', 10, 'solar-array-10', 'solar-array', 40, 40, 'Y', 40/2);
 insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('SLSU', 1, 'super-light-structural', 'super-light-structural', 0.005, 0.005, 'Y', 0.005/2);
 insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('STUN', 1, 'structural', 'structural', 0.5, 0.5, 'Y', 0.5/2);
 insert into units (code, tech_level, name, descr, mass_per_unit, volume_per_unit, hudnut, stowed_volume_per_unit) values ('TPT-1', 1, 'transport-1', 'transport', 4, 4, 'N', 1);
@@ -20,6 +20,9 @@ package jdb
 
 import "fmt"
 
+// upkeepPct is the share of a unit's metallics and non-metallics needed each turn to maintain it.
+const upkeepPct = 0.01
+
 func unitAttributes(name string, techLevel int) (mets, nmts, totalMassUnits, fuelPerTurn, fuelPerCombatRound float64) {
 	tl := float64(techLevel)
 	switch name {
@@ -30,7 +33,7 @@ func unitAttributes(name string, techLevel int) (mets, nmts, totalMassUnits, fue
 	case "assault-weapon":
 		return 1 * tl, 1 * tl, 2 * tl, 2 * tl * tl, 0
 	case "automation":
-		return 2 * tl, 2 * tl, 4 * tl, 0, 0
+		return 2 * tl, 2 * tl, 4 * tl, 0.5 * tl, 0
 	case "consumer-goods":
 		return 0.2, 0.4, 0.6, 0, 0
 	case "energy-shield":
@@ -53,7 +56,7 @@ func unitAttributes(name string, techLevel int) (mets, nmts, totalMassUnits, fue
 	case "gold":
 		return 0, 0, 1, 0, 0
 	case "hyper-drive":
-		return 25 * tl, 20 * tl, 45 * tl, 0, 0
+		return 25 * tl, 20 * tl, 45 * tl, 2 * tl, 0
 	case "life-support":
 		return 3 * tl, 5 * tl, 8 * tl, 1 * tl, 0
 	case "light-structural":
@@ -72,10 +75,14 @@ func unitAttributes(name string, techLevel int) (mets, nmts, totalMassUnits, fue
 		return 15 * tl, 10 * tl, 25 * tl, 0, 0
 	case "non-metallics":
 		return 0, 0, 1, 0, 0
+	case "power-plant":
+		return 4 * tl, 2 * tl, 6 * tl, tl, 0
 	case "sensor":
 		return 10 * tl, 20 * tl, 40 * tl, tl / 20, 0
+	case "solar-array":
+		return 1 * tl, 3 * tl, 4 * tl, 0, 0
 	case "space-drive":
-		return 15 * tl, 10 * tl, 25 * tl, 0, tl * tl
+		return 15 * tl, 10 * tl, 25 * tl, tl, tl * tl
 	case "structural":
 		return 0.1, 0.4, 0.5, 0, 0
 	case "super-light-structural":
@@ -85,3 +92,19 @@ func unitAttributes(name string, techLevel int) (mets, nmts, totalMassUnits, fue
 	}
 	panic(fmt.Sprintf("assert(unit.name != %q)", name))
 }
+
+// unitUpkeep returns the materials needed to maintain a single operational unit for one turn.
+// Machinery is maintained with metallics and non-metallics.
+// Electronics are maintained with consumer goods (spare parts).
+// Raw materials, supplies, and structural units don't need maintenance.
+func unitUpkeep(name string, techLevel int) (mets, nmts, cngd float64) {
+	switch name {
+	case "automation", "military-robots", "sensor":
+		return 0, 0, 0.1 * float64(techLevel)
+	case "anti-missile", "assault-craft", "assault-weapon", "energy-shield", "energy-weapon",
+		"factory", "farm", "hyper-drive", "life-support", "mine", "missile-launcher", "power-plant", "solar-array", "space-drive", "transport":
+		mets, nmts, _, _, _ = unitAttributes(name, techLevel)
+		return mets * upkeepPct, nmts * upkeepPct, 0
+	}
+	return 0, 0, 0
+}
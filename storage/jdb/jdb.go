@@ -150,7 +150,8 @@ func (g *Game) extractUnits(db *sql.DB) error {
 		unit.Kind = unit.Description
 		unit.Hudnut = hudnut == "Y"
 
-		unit.MetsPerUnit, unit.NonMetsPerUnit, _, unit.FuelPerUnitPerTurn, _ = unitAttributes(unit.Kind, unit.TechLevel)
+		unit.MetsPerUnit, unit.NonMetsPerUnit, _, unit.FuelPerUnitPerTurn, unit.FuelPerUnitPerCombatRound = unitAttributes(unit.Kind, unit.TechLevel)
+		unit.UpkeepMetsPerUnit, unit.UpkeepNmtsPerUnit, unit.UpkeepCngdPerUnit = unitUpkeep(unit.Kind, unit.TechLevel)
 
 		g.Units = append(g.Units, unit)
 	}
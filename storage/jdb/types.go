@@ -63,15 +63,19 @@ type EnclosedColony struct {
 	Hull                 HullUnits      `json:"hull,omitempty"`
 	Inventory            InventoryUnits `json:"inventory,omitempty"`
 	Population           struct {
-		ProfessionalQty        int     `json:"professional-qty,omitempty"`
-		SoldierQty             int     `json:"soldier-qty,omitempty"`
-		UnskilledQty           int     `json:"unskilled-qty,omitempty"`
-		UnemployedQty          int     `json:"unemployed-qty,omitempty"`
-		ConstructionCrewQty    int     `json:"construction-crew-qty,omitempty"`
-		SpyTeamQty             int     `json:"spy-team-qty,omitempty"`
-		RebelPct               float64 `json:"rebel-pct,omitempty"`
-		BirthsPriorTurn        int     `json:"births-prior-turn,omitempty"`
-		NaturalDeathsPriorTurn int     `json:"natural-deaths-prior-turn,omitempty"`
+		ProfessionalQty        int      `json:"professional-qty,omitempty"`
+		SoldierQty             int      `json:"soldier-qty,omitempty"`
+		UnskilledQty           int      `json:"unskilled-qty,omitempty"`
+		UnemployedQty          int      `json:"unemployed-qty,omitempty"`
+		ConstructionCrewQty    int      `json:"construction-crew-qty,omitempty"`
+		SpyTeamQty             int      `json:"spy-team-qty,omitempty"`
+		RebelPct               float64  `json:"rebel-pct,omitempty"`
+		BirthsPriorTurn        int      `json:"births-prior-turn,omitempty"`
+		NaturalDeathsPriorTurn int      `json:"natural-deaths-prior-turn,omitempty"`
+		ImmigrantsPriorTurn    int      `json:"immigrants-prior-turn,omitempty"`
+		EmigrantsPriorTurn     int      `json:"emigrants-prior-turn,omitempty"`
+		StandardOfLiving       float64  `json:"standard-of-living,omitempty"`
+		Training               Trainees `json:"training,omitempty"`
 	} `json:"population"`
 	Pay struct {
 		ProfessionalPct float64 `json:"professional-pct,omitempty"`
@@ -115,6 +119,7 @@ type FactoryGroup struct {
 	Stage2Qty int                  `json:"stage-2-qty,omitempty"`
 	Stage3Qty int                  `json:"stage-3-qty,omitempty"`
 	Stage4Qty int                  `json:"stage-4-qty,omitempty"`
+	IdleTurns int                  `json:"idle-turns,omitempty"` // turns the group is idle while retooling
 }
 
 type FactoryGroups []*FactoryGroup
@@ -195,6 +200,7 @@ type Game struct {
 	EnclosedColonies EnclosedColonies `json:"enclosed-colonies,omitempty"`
 	FactoryGroups    FactoryGroups    `json:"factory-groups,omitempty"`
 	FarmGroups       FarmGroups       `json:"farm-groups,omitempty"`
+	Market           MarketReports    `json:"market,omitempty"` // results of the last trade phase
 	MineGroups       MineGroups       `json:"mine-groups,omitempty"`
 	Nations          Nations          `json:"nations,omitempty"`
 	OrbitalColonies  OrbitalColonies  `json:"orbital-colonies,omitempty"`
@@ -246,6 +252,31 @@ func (u InventoryUnits) Swap(i, j int) {
 	u[i], u[j] = u[j], u[i]
 }
 
+// MarketReport is the activity for a single unit during the last trade phase.
+type MarketReport struct {
+	UnitId    int     `json:"unit-id"`
+	BidQty    int     `json:"bid-qty,omitempty"`    // units that buyers asked for
+	AskQty    int     `json:"ask-qty,omitempty"`    // units that sellers offered
+	Volume    int     `json:"volume,omitempty"`     // units that changed hands
+	LowPrice  int     `json:"low-price,omitempty"`  // in gold per unit
+	HighPrice int     `json:"high-price,omitempty"` // in gold per unit
+	AvgPrice  float64 `json:"avg-price,omitempty"`  // in gold per unit
+}
+
+type MarketReports []*MarketReport
+
+func (m MarketReports) Len() int {
+	return len(m)
+}
+
+func (m MarketReports) Less(i, j int) bool {
+	return m[i].UnitId < m[j].UnitId
+}
+
+func (m MarketReports) Swap(i, j int) {
+	m[i], m[j] = m[j], m[i]
+}
+
 // MineGroup is a group of mines working a single deposit.
 // All mine units in a group must be the same type and tech level.
 type MineGroup struct {
@@ -259,6 +290,7 @@ type MineGroup struct {
 	Stage2Qty int `json:"stage-2-qty,omitempty"`
 	Stage3Qty int `json:"stage-3-qty,omitempty"`
 	Stage4Qty int `json:"stage-4-qty,omitempty"`
+	IdleTurns int `json:"idle-turns,omitempty"` // turns the group is idle while retooling
 }
 
 type MineGroups []*MineGroup
@@ -285,26 +317,27 @@ func (m MineGroups) Swap(i, j int) {
 // players to control ships and colonies in the nation.
 // These players are called viceroys or regents.
 type Nation struct {
-	Id                   int      `json:"id"`                      // unique id for nation
-	No                   int      `json:"no"`                      // nation number, starts at 1
-	Name                 string   `json:"name"`                    // unique name for this nation
-	GovtName             string   `json:"govt-name"`               // name of the government
-	GovtKind             string   `json:"govt-kind"`               // kind of government
-	HomePlanetId         int      `json:"home-planet-id"`          // id of nation's home planet
-	ControlledByPlayerId int      `json:"controlled-by-player-id"` // id of player controlling this nation
-	Speciality           string   `json:"speciality"`              // nation's speciality for research
-	TechLevel            int      `json:"tech-level"`              // current tech level of the nation
-	ResearchPointsPool   int      `json:"research-points-pool"`    // points in pool
-	Skills               struct { // not used currently
-		Biology       int `json:"biology,omitempty"`       // not used currently
-		Bureaucracy   int `json:"bureaucracy,omitempty"`   // not used currently
-		Gravitics     int `json:"gravitics,omitempty"`     // not used currently
-		LifeSupport   int `json:"life-support,omitempty"`  // not used currently
-		Manufacturing int `json:"manufacturing,omitempty"` // not used currently
-		Military      int `json:"military,omitempty"`      // not used currently
-		Mining        int `json:"mining,omitempty"`        // not used currently
-		Shields       int `json:"shields,omitempty"`       // not used currently
-	} `json:"skills"` // not used currently
+	Id                   int     `json:"id"`                      // unique id for nation
+	No                   int     `json:"no"`                      // nation number, starts at 1
+	Name                 string  `json:"name"`                    // unique name for this nation
+	GovtName             string  `json:"govt-name"`               // name of the government
+	GovtKind             string  `json:"govt-kind"`               // kind of government
+	HomePlanetId         int     `json:"home-planet-id"`          // id of nation's home planet
+	ControlledByPlayerId int     `json:"controlled-by-player-id"` // id of player controlling this nation
+	Speciality           string  `json:"speciality"`              // nation's speciality for research
+	TechLevel            int     `json:"tech-level"`              // current tech level of the nation
+	ResearchPointsPool   int     `json:"research-points-pool"`    // points in pool
+	Surveys              Surveys `json:"surveys,omitempty"`       // planets the nation has surveyed
+	Skills               struct {
+		Biology       int `json:"biology,omitempty"`
+		Bureaucracy   int `json:"bureaucracy,omitempty"`
+		Gravitics     int `json:"gravitics,omitempty"`
+		LifeSupport   int `json:"life-support,omitempty"`
+		Manufacturing int `json:"manufacturing,omitempty"`
+		Military      int `json:"military,omitempty"`
+		Mining        int `json:"mining,omitempty"`
+		Shields       int `json:"shields,omitempty"`
+	} `json:"skills"`
 }
 
 type Nations []*Nation
@@ -336,15 +369,19 @@ type OrbitalColony struct {
 	Hull                 HullUnits      `json:"hull,omitempty"`
 	Inventory            InventoryUnits `json:"inventory,omitempty"`
 	Population           struct {
-		ProfessionalQty        int     `json:"professional-qty,omitempty"`
-		SoldierQty             int     `json:"soldier-qty,omitempty"`
-		UnskilledQty           int     `json:"unskilled-qty,omitempty"`
-		UnemployedQty          int     `json:"unemployed-qty,omitempty"`
-		ConstructionCrewQty    int     `json:"construction-crew-qty,omitempty"`
-		SpyTeamQty             int     `json:"spy-team-qty,omitempty"`
-		RebelPct               float64 `json:"rebel-pct,omitempty"`
-		BirthsPriorTurn        int     `json:"births-prior-turn,omitempty"`
-		NaturalDeathsPriorTurn int     `json:"natural-deaths-prior-turn,omitempty"`
+		ProfessionalQty        int      `json:"professional-qty,omitempty"`
+		SoldierQty             int      `json:"soldier-qty,omitempty"`
+		UnskilledQty           int      `json:"unskilled-qty,omitempty"`
+		UnemployedQty          int      `json:"unemployed-qty,omitempty"`
+		ConstructionCrewQty    int      `json:"construction-crew-qty,omitempty"`
+		SpyTeamQty             int      `json:"spy-team-qty,omitempty"`
+		RebelPct               float64  `json:"rebel-pct,omitempty"`
+		BirthsPriorTurn        int      `json:"births-prior-turn,omitempty"`
+		NaturalDeathsPriorTurn int      `json:"natural-deaths-prior-turn,omitempty"`
+		ImmigrantsPriorTurn    int      `json:"immigrants-prior-turn,omitempty"`
+		EmigrantsPriorTurn     int      `json:"emigrants-prior-turn,omitempty"`
+		StandardOfLiving       float64  `json:"standard-of-living,omitempty"`
+		Training               Trainees `json:"training,omitempty"`
 	} `json:"population"`
 	Pay struct {
 		ProfessionalPct float64 `json:"professional-pct,omitempty"`
@@ -406,11 +443,15 @@ func (p Planets) Swap(i, j int) {
 
 // Player is a position in the game.
 type Player struct {
-	Id                int    `json:"id"`                          // unique id for a player, starts at 1
-	UserId            int    `json:"user-id"`                     // user that controls this player
-	Name              string `json:"name"`                        // unique name for this player
-	MemberOf          int    `json:"member-of"`                   // nation the player is aligned with
-	ReportsToPlayerId int    `json:"reports-to-player,omitempty"` // player that this player reports to
+	Id                int      `json:"id"`                          // unique id for a player, starts at 1
+	UserId            int      `json:"user-id"`                     // user that controls this player
+	Name              string   `json:"name"`                        // unique name for this player
+	MemberOf          int      `json:"member-of"`                   // nation the player is aligned with
+	ReportsToPlayerId int      `json:"reports-to-player,omitempty"` // player that this player reports to
+	CombatReport      []string `json:"combat-report,omitempty"`     // results of the last combat phase
+	EspionageReport   []string `json:"espionage-report,omitempty"`  // results of the last espionage phase
+	UnrestReport      []string `json:"unrest-report,omitempty"`     // results of the last unrest phase
+	StandingOrders    []string `json:"standing-orders,omitempty"`   // orders that are repeated every turn
 }
 
 type Players []*Player
@@ -442,14 +483,18 @@ type Ship struct {
 	Hull                 HullUnits      `json:"hull,omitempty"`
 	Inventory            InventoryUnits `json:"inventory,omitempty"`
 	Population           struct {
-		ProfessionalQty        int     `json:"professional-qty,omitempty"`
-		SoldierQty             int     `json:"soldier-qty,omitempty"`
-		UnskilledQty           int     `json:"unskilled-qty,omitempty"`
-		UnemployedQty          int     `json:"unemployed-qty,omitempty"`
-		ConstructionCrewQty    int     `json:"construction-crew-qty,omitempty"`
-		SpyTeamQty             int     `json:"spy-team-qty,omitempty"`
-		RebelPct               float64 `json:"rebel-pct,omitempty"`
-		NaturalDeathsPriorTurn int     `json:"natural-deaths-prior-turn,omitempty"`
+		ProfessionalQty        int      `json:"professional-qty,omitempty"`
+		SoldierQty             int      `json:"soldier-qty,omitempty"`
+		UnskilledQty           int      `json:"unskilled-qty,omitempty"`
+		UnemployedQty          int      `json:"unemployed-qty,omitempty"`
+		ConstructionCrewQty    int      `json:"construction-crew-qty,omitempty"`
+		SpyTeamQty             int      `json:"spy-team-qty,omitempty"`
+		RebelPct               float64  `json:"rebel-pct,omitempty"`
+		NaturalDeathsPriorTurn int      `json:"natural-deaths-prior-turn,omitempty"`
+		ImmigrantsPriorTurn    int      `json:"immigrants-prior-turn,omitempty"`
+		EmigrantsPriorTurn     int      `json:"emigrants-prior-turn,omitempty"`
+		StandardOfLiving       float64  `json:"standard-of-living,omitempty"`
+		Training               Trainees `json:"training,omitempty"`
 	} `json:"population"`
 	Pay struct {
 		ProfessionalPct float64 `json:"professional-pct,omitempty"`
@@ -517,15 +562,19 @@ type SurfaceColony struct {
 	Hull                 HullUnits      `json:"hull,omitempty"`
 	Inventory            InventoryUnits `json:"inventory,omitempty"`
 	Population           struct {
-		ProfessionalQty        int     `json:"professional-qty,omitempty"`
-		SoldierQty             int     `json:"soldier-qty,omitempty"`
-		UnskilledQty           int     `json:"unskilled-qty,omitempty"`
-		UnemployedQty          int     `json:"unemployed-qty,omitempty"`
-		ConstructionCrewQty    int     `json:"construction-crew-qty,omitempty"`
-		SpyTeamQty             int     `json:"spy-team-qty,omitempty"`
-		RebelPct               float64 `json:"rebel-pct,omitempty"`
-		BirthsPriorTurn        int     `json:"births-prior-turn,omitempty"`
-		NaturalDeathsPriorTurn int     `json:"natural-deaths-prior-turn,omitempty"`
+		ProfessionalQty        int      `json:"professional-qty,omitempty"`
+		SoldierQty             int      `json:"soldier-qty,omitempty"`
+		UnskilledQty           int      `json:"unskilled-qty,omitempty"`
+		UnemployedQty          int      `json:"unemployed-qty,omitempty"`
+		ConstructionCrewQty    int      `json:"construction-crew-qty,omitempty"`
+		SpyTeamQty             int      `json:"spy-team-qty,omitempty"`
+		RebelPct               float64  `json:"rebel-pct,omitempty"`
+		BirthsPriorTurn        int      `json:"births-prior-turn,omitempty"`
+		NaturalDeathsPriorTurn int      `json:"natural-deaths-prior-turn,omitempty"`
+		ImmigrantsPriorTurn    int      `json:"immigrants-prior-turn,omitempty"`
+		EmigrantsPriorTurn     int      `json:"emigrants-prior-turn,omitempty"`
+		StandardOfLiving       float64  `json:"standard-of-living,omitempty"`
+		Training               Trainees `json:"training,omitempty"`
 	} `json:"population"`
 	Pay struct {
 		ProfessionalPct float64 `json:"professional-pct,omitempty"`
@@ -557,6 +606,39 @@ func (s SurfaceColonies) Swap(i, j int) {
 	s[i], s[j] = s[j], s[i]
 }
 
+// Survey is what a nation learned about a planet the last time it was surveyed.
+// It is a snapshot; the planet may have changed since the survey was made.
+type Survey struct {
+	PlanetId       int              `json:"planet-id"`
+	Year           int              `json:"year"`    // year the survey was made
+	Quarter        int              `json:"quarter"` // quarter the survey was made
+	Kind           string           `json:"kind"`
+	HabitabilityNo int              `json:"habitability-no,omitempty"`
+	Deposits       []*SurveyDeposit `json:"deposits,omitempty"`
+}
+
+type Surveys []*Survey
+
+func (s Surveys) Len() int {
+	return len(s)
+}
+
+func (s Surveys) Less(i, j int) bool {
+	return s[i].PlanetId < s[j].PlanetId
+}
+
+func (s Surveys) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+// SurveyDeposit is what a nation learned about a deposit during a survey.
+type SurveyDeposit struct {
+	No           int     `json:"no"`      // number of deposit on planet
+	UnitId       int     `json:"unit-id"` // fuel, gold, metallic, non-metallic
+	YieldPct     float64 `json:"yield-pct"`
+	RemainingQty int     `json:"remaining-qty"` // in metric tonnes
+}
+
 // System is a system in the game.
 // It contains zero or more stars.
 type System struct {
@@ -579,21 +661,34 @@ func (s Systems) Swap(i, j int) {
 	s[i], s[j] = s[j], s[i]
 }
 
+// Trainee is drafted population that is still in training.
+type Trainee struct {
+	Class     string `json:"class"`
+	Qty       int    `json:"qty"`
+	TurnsLeft int    `json:"turns-left"`
+}
+
+type Trainees []*Trainee
+
 // Unit is a thing in the game.
 type Unit struct {
-	Id                  int     `json:"id"` // unique identifier
-	Kind                string  `json:"kind"`
-	Code                string  `json:"code"`
-	TechLevel           int     `json:"tech-level,omitempty"`
-	Name                string  `json:"name"`
-	Description         string  `json:"description,omitempty"`
-	MassPerUnit         float64 `json:"mass-per-unit"`          // mass (in metric tonnes) of a single unit
-	VolumePerUnit       float64 `json:"volume-per-unit"`        // volume (in cubic meters) of a single unit
-	Hudnut              bool    `json:"hudnut,omitempty"`       // if true, unit can be disassembled when stowed
-	StowedVolumePerUnit float64 `json:"stowed-volume-per-unit"` // volume (in cubic meters) of a single unit when stowed
-	FuelPerUnitPerTurn  float64 `json:"fuel-per-unit-per-turn,omitempty"`
-	MetsPerUnit         float64 `json:"mets-per-unit,omitempty"`
-	NonMetsPerUnit      float64 `json:"non-mets-per-unit,omitempty"`
+	Id                        int     `json:"id"` // unique identifier
+	Kind                      string  `json:"kind"`
+	Code                      string  `json:"code"`
+	TechLevel                 int     `json:"tech-level,omitempty"`
+	Name                      string  `json:"name"`
+	Description               string  `json:"description,omitempty"`
+	MassPerUnit               float64 `json:"mass-per-unit"`          // mass (in metric tonnes) of a single unit
+	VolumePerUnit             float64 `json:"volume-per-unit"`        // volume (in cubic meters) of a single unit
+	Hudnut                    bool    `json:"hudnut,omitempty"`       // if true, unit can be disassembled when stowed
+	StowedVolumePerUnit       float64 `json:"stowed-volume-per-unit"` // volume (in cubic meters) of a single unit when stowed
+	FuelPerUnitPerTurn        float64 `json:"fuel-per-unit-per-turn,omitempty"`
+	FuelPerUnitPerCombatRound float64 `json:"fuel-per-unit-per-combat-round,omitempty"`
+	MetsPerUnit               float64 `json:"mets-per-unit,omitempty"`
+	NonMetsPerUnit            float64 `json:"non-mets-per-unit,omitempty"`
+	UpkeepMetsPerUnit         float64 `json:"upkeep-mets-per-unit,omitempty"` // metallics needed per turn to maintain a single unit
+	UpkeepNmtsPerUnit         float64 `json:"upkeep-nmts-per-unit,omitempty"` // non-metallics needed per turn to maintain a single unit
+	UpkeepCngdPerUnit         float64 `json:"upkeep-cngd-per-unit,omitempty"` // consumer goods needed per turn to maintain a single unit
 }
 
 type Units []*Unit
@@ -0,0 +1,82 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+// automationWorkersPerTechLevel is the number of unskilled workers that
+// a single automation unit replaces at each tech level.
+const automationWorkersPerTechLevel = 1
+
+// automationCapacity returns the number of unskilled workers that the
+// active automation units in the hull and inventory can replace.
+func automationCapacity(cs *CorS) int {
+	capacity := 0
+	for _, u := range cs.Hull {
+		if u.Unit.Kind == "automation" {
+			capacity += u.ActiveQty * u.Unit.TechLevel * automationWorkersPerTechLevel
+		}
+	}
+	for _, u := range cs.Inventory {
+		if u.Unit.Kind == "automation" {
+			capacity += u.ActiveQty * u.Unit.TechLevel * automationWorkersPerTechLevel
+		}
+	}
+	return capacity
+}
+
+// automationInitialization loads the automation pool with as many
+// automation units as there is fuel to run them. the fuel is consumed
+// whether the units end up replacing any workers or not.
+func automationInitialization(cs *CorS) {
+	for _, units := range []InventoryUnits{cs.Hull, cs.Inventory} {
+		for _, u := range units {
+			if u.Unit.Kind != "automation" || u.ActiveQty == 0 {
+				continue
+			}
+			qty := u.ActiveQty
+			if !isZero(u.Unit.FuelPerUnitPerTurn) {
+				if limit := int(float64(availableFuel(cs)) / u.Unit.FuelPerUnitPerTurn); limit < qty {
+					qty = limit
+				}
+			}
+			fuel := u.Unit.fuelUsed(qty)
+			burnFuel(cs, fuel)
+			cs.aut.operational += qty * u.Unit.TechLevel * automationWorkersPerTechLevel
+			cs.Log("  %-7s %13d of %13d active  %13d FUEL  %13d UNS replaced\n",
+				u.Unit.Code, qty, u.ActiveQty, fuel, qty*u.Unit.TechLevel*automationWorkersPerTechLevel)
+		}
+	}
+}
+
+// allocateUnskilled allocates unskilled labor, drawing on automation units
+// before unskilled workers. it returns the number of workers replaced by automation.
+func allocateUnskilled(cs *CorS, qty int) int {
+	aut := qty
+	if aut > availableAut(cs) {
+		aut = availableAut(cs)
+	}
+	cs.aut.allocated += aut
+	cs.uns.allocated += qty - aut
+	return aut
+}
+
+// availableUnskilled returns the unskilled labor available from both
+// unskilled workers and automation units.
+func availableUnskilled(cs *CorS) int {
+	return availableUns(cs) + availableAut(cs)
+}
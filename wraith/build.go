@@ -0,0 +1,223 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// buildingTonnesPerCrew is the mass of structural units that a single
+// construction crew can build into a new hull in a turn.
+const buildingTonnesPerCrew = 500
+
+// ExecuteBuildPhase runs all the orders in the build phase.
+func (e *Engine) ExecuteBuildPhase(pos []*PhaseOrders) (errs []error) {
+	for _, o := range pos {
+		o.Player.Log("\n\nBuild -----------------------------------------------------------\n")
+		for _, order := range o.Build {
+			if o.skip(e, order) {
+				continue
+			}
+			if err := order.Colony.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+			if err := order.Ship.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// Execute founds a new enclosed or surface colony in an orbit in the same system.
+// The hull is built from stowed structural units and the work needs construction crews.
+// Surface colonies can only be built on planets that can support an open colony.
+// Will fail if the colony or ship is not controlled by the player.
+func (o *BuildColonyOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	target := fmt.Sprintf("%s%s#%d", o.Coords.String(), o.Star, o.OrbitNo)
+	p.Log("  build colony %s: %s %s %13d %s\n", o.CorS, target, o.Kind, o.Quantity, o.Unit)
+	cs, err := findControlled(e, p, "build colony", o.CorS)
+	if err != nil {
+		return err
+	}
+	kind := strings.ToLower(o.Kind)
+	if !(kind == "enclosed" || kind == "surface") {
+		p.Log("               %s: colony must be enclosed or surface\n", o.CorS)
+		return fmt.Errorf("%s: invalid colony kind %q", o.CorS, o.Kind)
+	}
+	if cs.Planet == nil || cs.Planet.System.Coords != o.Coords {
+		p.Log("               %s: not in system %s\n", o.CorS, o.Coords.String())
+		return fmt.Errorf("%s: not in system %s", o.CorS, o.Coords.String())
+	}
+	planet, ok := e.findPlanet(o.Coords, o.Star, o.OrbitNo)
+	if !ok || planet.Kind == "empty" {
+		p.Log("               %s: no planet at %s\n", o.CorS, target)
+		return fmt.Errorf("%s: no planet at %s", o.CorS, target)
+	} else if kind == "surface" && !openColonyViable(planet) {
+		p.Log("               %s: %s can't support a surface colony\n", o.CorS, target)
+		return fmt.Errorf("%s: %s can't support a surface colony", o.CorS, target)
+	}
+	for _, colony := range planet.Colonies {
+		if colony.Kind == kind || (kind == "surface" && colony.Kind == "open") {
+			p.Log("               %s: %s already has a %s colony\n", o.CorS, target, kind)
+			return fmt.Errorf("%s: %s already has a %s colony", o.CorS, target, kind)
+		}
+	}
+
+	u, qty, err := buildHull(e, p, "build colony", cs, o.Quantity, o.Unit)
+	if err != nil {
+		return err
+	}
+	colony := newCorS(e, p, cs, kind, planet, u, qty)
+	e.Colonies[colony.HullId] = colony
+	planet.Colonies = append(planet.Colonies, colony)
+	sort.Sort(planet.Colonies)
+	p.Colonies = append(p.Colonies, colony)
+	sort.Sort(p.Colonies)
+	p.Log("               %s: built %s colony %s at %s\n", o.CorS, kind, colony.HullId, target)
+
+	return nil
+}
+
+// Execute builds a new ship in the same orbit as the colony or ship building it.
+// The hull is built from stowed structural units and the work needs construction crews.
+// Will fail if the colony or ship is not controlled by the player.
+func (o *BuildShipOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	p.Log("  build ship %s: %13d %s\n", o.CorS, o.Quantity, o.Unit)
+	cs, err := findControlled(e, p, "build ship", o.CorS)
+	if err != nil {
+		return err
+	} else if cs.Planet == nil {
+		p.Log("             %s: not in orbit\n", o.CorS)
+		return fmt.Errorf("%s: not in orbit", o.CorS)
+	}
+
+	u, qty, err := buildHull(e, p, "build ship", cs, o.Quantity, o.Unit)
+	if err != nil {
+		return err
+	}
+	ship := newCorS(e, p, cs, "ship", cs.Planet, u, qty)
+	e.Ships[ship.HullId] = ship
+	cs.Planet.Ships = append(cs.Planet.Ships, ship)
+	sort.Sort(cs.Planet.Ships)
+	p.Ships = append(p.Ships, ship)
+	sort.Sort(p.Ships)
+	p.Log("             %s: built ship %s\n", o.CorS, ship.HullId)
+
+	return nil
+}
+
+// buildHull removes the stowed structural units for a new hull and allocates the construction crews.
+// It returns the structural unit and the number of units actually used.
+func buildHull(e *Engine, p *Player, verb string, cs *CorS, quantity int, unit string) (*Unit, int, error) {
+	if quantity <= 0 {
+		p.Log("  %s %s: nothing to do\n", verb, cs.HullId)
+		return nil, 0, fmt.Errorf("%s: nothing to build", cs.HullId)
+	}
+	if p.MemberOf == nil {
+		p.Log("  %s %s: player is not a member of a nation\n", verb, cs.HullId)
+		return nil, 0, fmt.Errorf("%s: player is not a member of a nation", cs.HullId)
+	}
+	u, ok := unitFromString(e, unit)
+	if !ok {
+		p.Log("  %s %s: no such unit %q\n", verb, cs.HullId, unit)
+		return nil, 0, fmt.Errorf("no such unit %q", unit)
+	} else if !(u.Code == "STUN" || u.Code == "LTSU" || u.Code == "SLSU") {
+		p.Log("  %s %s: %q is not a structural unit\n", verb, cs.HullId, unit)
+		return nil, 0, fmt.Errorf("%q: not a structural unit", unit)
+	}
+	var inventory *InventoryUnit
+	for _, iu := range cs.Inventory {
+		if iu.Unit.Id == u.Id {
+			inventory = iu
+			break
+		}
+	}
+	if inventory == nil {
+		p.Log("  %s %s: %q: not in inventory\n", verb, cs.HullId, unit)
+		return nil, 0, fmt.Errorf("%q: not in inventory", unit)
+	}
+
+	qty := quantity
+	if inventory.stowed.available() < qty {
+		qty = inventory.stowed.available()
+	}
+	p.Log("        %s: %-20s  %12d requested  %13d available\n", cs.HullId, u.Name, quantity, inventory.stowed.available())
+
+	consRequested := int(math.Ceil(float64(qty) * u.MassPerUnit / buildingTonnesPerCrew))
+	p.Log("        %s: %-20s  %12d requested  %13d available\n", cs.HullId, "construction-crew", consRequested, availableCon(cs))
+	if availableCon(cs) < consRequested {
+		consRequested = availableCon(cs)
+		qty = int(math.Floor(float64(consRequested) * buildingTonnesPerCrew / u.MassPerUnit))
+	}
+	if qty <= 0 {
+		p.Log("  %s %s: unable to build with %q\n", verb, cs.HullId, unit)
+		return nil, 0, fmt.Errorf("%s: unable to build with %q", cs.HullId, unit)
+	}
+	cs.cons.allocated += consRequested
+	inventory.stowed.remove(qty)
+
+	return u, qty, nil
+}
+
+// newCorS creates a colony or ship with a fresh MSN and a hull of structural units.
+// The new colony or ship starts with the builder's pay and rations.
+func newCorS(e *Engine, p *Player, builder *CorS, kind string, planet *Planet, u *Unit, qty int) *CorS {
+	cs := &CorS{
+		Id:           e.NextSeq(),
+		Kind:         kind,
+		MSN:          e.nextMSN(),
+		BuiltBy:      p.MemberOf,
+		TechLevel:    builder.TechLevel,
+		ControlledBy: p,
+		Planet:       planet,
+		Hull:         InventoryUnits{{Unit: u, ActiveQty: qty}},
+		Pay:          builder.Pay,
+		Rations:      builder.Rations,
+	}
+	if kind == "ship" {
+		cs.HullId = fmt.Sprintf("S%d", cs.MSN)
+	} else {
+		cs.HullId = fmt.Sprintf("C%d", cs.MSN)
+	}
+	cs.InitializeInventory()
+	e.CorSById[cs.Id] = cs
+	return cs
+}
+
+// nextMSN returns the next manufacturer serial number.
+// Colonies and ships share the same sequence.
+func (e *Engine) nextMSN() int {
+	msn := 0
+	for _, cs := range e.sortedCorS() {
+		if cs.MSN > msn {
+			msn = cs.MSN
+		}
+	}
+	return msn + 1
+}
@@ -0,0 +1,490 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	// combatRounds is the maximum number of rounds in a single engagement
+	combatRounds = 10
+	// assaultCraftCapacity is the number of soldiers carried per assault craft per tech level
+	assaultCraftCapacity = 10
+	// beamDamage is the mass (in metric tonnes) destroyed per energy weapon per tech level
+	beamDamage = 50
+	// groundLethality is the ground strength needed to kill one population unit in a round
+	groundLethality = 10
+	// missileDamage is the mass (in metric tonnes) destroyed per missile per tech level
+	missileDamage = 100
+	// raidCapacity is the mass (in metric tonnes) of cargo each surviving soldier carries off
+	raidCapacity = 1
+	// shieldSkillBonus is the added shield strength for each level of the nation's shields skill
+	shieldSkillBonus = 0.1
+	// shieldStrength is the damage (in metric tonnes) absorbed per energy shield per tech level each round
+	shieldStrength = 200
+)
+
+// combatant tracks the results for one side of an engagement
+type combatant struct {
+	cs           *CorS
+	landed       int // soldiers landed by assault craft that survived the round
+	missiles     int // missiles fired
+	intercepted  int // missiles fired by this side that were intercepted
+	beams        int // energy weapons fired
+	absorbed     int // damage absorbed by this side's shields
+	unitsLost    int // hull units destroyed
+	soldiersLost int // soldiers killed in ground combat
+	deaths       int // population killed
+}
+
+// ExecuteCombatPhase runs all the orders in the combat phase.
+// Defend orders are processed first so that the posture applies to every engagement.
+func (e *Engine) ExecuteCombatPhase(pos []*PhaseOrders) (errs []error) {
+	for _, o := range pos {
+		o.Player.Log("\n\nCombat ----------------------------------------------------------\n")
+	}
+	for _, cs := range e.sortedCorS() {
+		cs.defending = false
+		// soldiers can't fight unless they're in the labor pool
+		if !cs.laborLoaded {
+			laborInitialization(cs, pos)
+		}
+	}
+	for _, o := range pos {
+		for _, order := range o.Combat {
+			if o.skip(e, order) {
+				continue
+			}
+			if err := order.Defend.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	for _, o := range pos {
+		for _, order := range o.Combat {
+			if o.skip(e, order) {
+				continue
+			}
+			if err := order.Attack.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+			if err := order.Raid.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// Execute attacks another colony or ship at the same location.
+// Will fail if the attacker is not controlled by the player or the target is not in the same orbit.
+func (o *AttackOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	p.Log("  attack %s: %s\n", o.CorS, o.Target)
+	attacker, target, err := findCombatants(e, p, "attack", o.CorS, o.Target)
+	if err != nil {
+		return err
+	}
+
+	a, d := &combatant{cs: attacker}, &combatant{cs: target}
+	rounds := 0
+	for rounds < combatRounds {
+		rounds++
+		space, ground := spaceCombatRound(a, d), groundCombatRound(a, d)
+		if !space && !ground {
+			// neither side was able to do any damage
+			break
+		}
+		if totalPop(target) == 0 && hullMass(target) == 0 {
+			break
+		}
+	}
+	p.Log("         %s: %d rounds\n", o.CorS, rounds)
+
+	combatUnrest(a)
+	combatUnrest(d)
+	combatReport(fmt.Sprintf("%s attacked %s at %s (%d rounds)", attacker.HullId, target.HullId, attacker.Planet.String(), rounds), a, d)
+
+	return nil
+}
+
+// Execute sets a colony or ship to defend itself for the rest of the combat phase.
+// Will fail if the colony or ship is not controlled by the player.
+func (o *DefendOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	// find colony or ship
+	cs, ok := e.findColony(o.CorS)
+	if !ok {
+		if cs, ok = e.findShip(o.CorS); !ok {
+			p.Log("  defend %s: no such colony or ship\n", o.CorS)
+			return fmt.Errorf("no such colony or ship %q", o.CorS)
+		}
+	}
+	// fail if controlled by another player
+	if cs.ControlledBy != nil && cs.ControlledBy != p {
+		p.Log("  defend %s: no such colony or ship\n", o.CorS)
+		return fmt.Errorf("no such colony or ship %q", o.CorS)
+	}
+	cs.defending = true
+	p.Log("  defend %s: defending\n", o.CorS)
+	return nil
+}
+
+// Execute raids another colony or ship at the same location.
+// A raid is a single round of ground combat.
+// If the raiders defeat the defending soldiers, they carry off as much cargo as they can.
+// Will fail if the raider is not controlled by the player or the target is not in the same orbit.
+func (o *RaidOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	p.Log("  raid %s: %s %s\n", o.CorS, o.Target, o.Cargo)
+	attacker, target, err := findCombatants(e, p, "raid", o.CorS, o.Target)
+	if err != nil {
+		return err
+	}
+	cargo, ok := unitFromString(e, o.Cargo)
+	if !ok {
+		p.Log("       %s: no such unit %q\n", o.CorS, o.Cargo)
+		return fmt.Errorf("no such unit %q", o.Cargo)
+	}
+
+	a, d := &combatant{cs: attacker}, &combatant{cs: target}
+	groundCombatRound(a, d)
+	title := fmt.Sprintf("%s raided %s at %s", attacker.HullId, target.HullId, attacker.Planet.String())
+	if a.landed == 0 || availableSol(target) > 0 {
+		p.Log("       %s: raid failed\n", o.CorS)
+		combatUnrest(a)
+		combatUnrest(d)
+		combatReport(title+": raid failed", a, d)
+		return nil
+	}
+
+	// find the cargo on the target
+	var from *InventoryUnit
+	for _, u := range target.Inventory {
+		if u.Unit.Id == cargo.Id {
+			from = u
+			break
+		}
+	}
+	qty := 0
+	if from != nil {
+		qty = int(float64(a.landed*raidCapacity) / cargo.MassPerUnit)
+		if from.stowed.available() < qty {
+			qty = from.stowed.available()
+		}
+	}
+	if qty > 0 {
+		from.stowed.destroy(qty)
+		var to *InventoryUnit
+		for _, u := range attacker.Inventory {
+			if u.Unit.Id == cargo.Id {
+				to = u
+				break
+			}
+		}
+		if to == nil {
+			to = &InventoryUnit{Unit: cargo}
+			attacker.Inventory = append(attacker.Inventory, to)
+			sort.Sort(attacker.Inventory)
+		}
+		to.stowed.create(qty)
+	}
+	p.Log("       %s: carried off %d %s\n", o.CorS, qty, cargo.Code)
+	combatUnrest(a)
+	combatUnrest(d)
+	combatReport(fmt.Sprintf("%s: carried off %d %s", title, qty, cargo.Code), a, d)
+
+	return nil
+}
+
+// combatReport adds the results of an engagement to the reports of the players involved.
+func combatReport(title string, a, d *combatant) {
+	lines := []string{title}
+	for _, c := range []*combatant{a, d} {
+		lines = append(lines, fmt.Sprintf("    %-8s  missiles fired %8d  intercepted %8d  beams fired %8d  absorbed %8d  units lost %8d  soldiers lost %8d  deaths %8d",
+			c.cs.HullId, c.missiles, c.intercepted, c.beams, c.absorbed, c.unitsLost, c.soldiersLost, c.deaths))
+	}
+	for _, cs := range []*CorS{a.cs, d.cs} {
+		if cs.ControlledBy == nil || (cs == d.cs && cs.ControlledBy == a.cs.ControlledBy) {
+			continue
+		}
+		cs.ControlledBy.CombatReport = append(cs.ControlledBy.CombatReport, lines...)
+	}
+}
+
+// findCombatants returns the attacker and target for an attack or raid.
+func findCombatants(e *Engine, p *Player, verb, id, targetId string) (attacker, target *CorS, err error) {
+	// find colony or ship
+	attacker, ok := e.findColony(id)
+	if !ok {
+		if attacker, ok = e.findShip(id); !ok {
+			p.Log("  %s %s: no such colony or ship\n", verb, id)
+			return nil, nil, fmt.Errorf("no such colony or ship %q", id)
+		}
+	}
+	// fail if controlled by another player
+	if attacker.ControlledBy != nil && attacker.ControlledBy != p {
+		p.Log("  %s %s: no such colony or ship\n", verb, id)
+		return nil, nil, fmt.Errorf("no such colony or ship %q", id)
+	}
+	// the target must be in the same orbit
+	target, ok = e.findColony(targetId)
+	if !ok {
+		target, ok = e.findShip(targetId)
+	}
+	if !ok || target.Planet != attacker.Planet {
+		p.Log("  %s %s: no such target %s\n", verb, id, targetId)
+		return nil, nil, fmt.Errorf("no such target %q", targetId)
+	} else if target == attacker {
+		p.Log("  %s %s: can not target self\n", verb, id)
+		return nil, nil, fmt.Errorf("%s: can not target self", id)
+	}
+	return attacker, target, nil
+}
+
+// spaceCombatRound runs a single round of missile and energy weapon fire
+// between the two sides. both sides fire before shields and damage are applied.
+// returns true if either side did any damage or had damage absorbed by shields.
+func spaceCombatRound(a, d *combatant) bool {
+	aDamage := missileVolley(a, d) + beamVolley(a)
+	dDamage := missileVolley(d, a) + beamVolley(d)
+	if !d.cs.defending {
+		// targets caught off guard return fire at half strength
+		dDamage = dDamage / 2
+	}
+	aAbsorbed, dAbsorbed := a.absorbed, d.absorbed
+	aDamage, dDamage = shieldsAbsorb(d, aDamage), shieldsAbsorb(a, dDamage)
+	aHit, dHit := applyDamage(d, aDamage), applyDamage(a, dDamage)
+	return aHit || dHit || a.absorbed != aAbsorbed || d.absorbed != dAbsorbed
+}
+
+// beamVolley fires the energy weapons of one side and returns the damage done.
+// energy weapons can't be intercepted, only absorbed by shields.
+func beamVolley(from *combatant) float64 {
+	damage := 0.0
+	for _, u := range operationalUnits(from.cs, "energy-weapon") {
+		n := poweredUnits(from.cs, u)
+		from.beams += n
+		damage += float64(n * beamDamage * u.Unit.TechLevel)
+	}
+	return damage
+}
+
+// shieldsAbsorb powers the energy shields of one side for a round and
+// returns the damage that gets through them. shield strength is raised
+// by the shields skill of the nation that controls the colony or ship.
+func shieldsAbsorb(c *combatant, damage float64) float64 {
+	strength := 0.0
+	for _, u := range operationalUnits(c.cs, "energy-shield") {
+		strength += float64(poweredUnits(c.cs, u) * u.Unit.TechLevel * shieldStrength)
+	}
+	if c.cs.ControlledBy != nil && c.cs.ControlledBy.MemberOf != nil {
+		strength *= 1 + shieldSkillBonus*float64(c.cs.ControlledBy.MemberOf.Skills.Shields)
+	}
+	absorbed := math.Min(strength, damage)
+	c.absorbed += int(absorbed)
+	return damage - absorbed
+}
+
+// missileVolley fires the missile launchers of one side and
+// returns the damage that gets past the other side's anti-missiles.
+func missileVolley(from, to *combatant) float64 {
+	shots := 0
+	for _, u := range operationalUnits(from.cs, "missile-launcher") {
+		shots += poweredUnits(from.cs, u) * u.Unit.TechLevel
+	}
+	// missiles may be carried in the hull or in inventory
+	fired, damage := 0, 0.0
+	for _, units := range []InventoryUnits{from.cs.Hull, from.cs.Inventory} {
+		for _, u := range units {
+			if shots == 0 {
+				break
+			} else if u.Unit.Kind != "missile" {
+				continue
+			}
+			n := shots
+			if available := u.operational.available() + u.stowed.available(); available < n {
+				n = available
+			}
+			// use assembled missiles before stowed ones
+			if m := u.operational.destroy(n); m < n {
+				u.stowed.destroy(n - m)
+			}
+			shots, fired = shots-n, fired+n
+			damage += float64(n * missileDamage * u.Unit.TechLevel)
+		}
+	}
+	if fired == 0 {
+		return 0
+	}
+
+	interceptors := 0
+	for _, u := range operationalUnits(to.cs, "anti-missile") {
+		interceptors += poweredUnits(to.cs, u) * u.Unit.TechLevel
+	}
+	intercepted := fired
+	if interceptors < intercepted {
+		intercepted = interceptors
+	}
+	from.missiles, from.intercepted = from.missiles+fired, from.intercepted+intercepted
+
+	return damage * float64(fired-intercepted) / float64(fired)
+}
+
+// applyDamage destroys hull units and kills population in proportion
+// to the damage taken relative to the remaining hull mass.
+// returns true if anything was destroyed.
+func applyDamage(c *combatant, damage float64) bool {
+	mass := hullMass(c.cs)
+	if damage <= 0 || mass == 0 {
+		return false
+	}
+	pct := damage / float64(mass)
+	if pct > 1 {
+		pct = 1
+	}
+	lost := 0
+	for _, u := range c.cs.Hull {
+		lost += u.operational.destroy(int(pct * float64(u.operational.initial-u.operational.destroyed)))
+	}
+	deaths := int(pct * float64(totalPop(c.cs)))
+	killProportionally(c.cs, deaths)
+	c.unitsLost, c.deaths = c.unitsLost+lost, c.deaths+deaths
+	return lost != 0 || deaths != 0
+}
+
+// groundCombatRound runs a single round of ground combat between the soldiers
+// carried over by the attacker's assault craft and the defender's soldiers.
+// casualties come out of the soldiers first; once the defending soldiers are
+// gone, the remaining casualties come out of the general population.
+// returns true if either side did any damage.
+func groundCombatRound(a, d *combatant) bool {
+	landed := landedSoldiers(a.cs)
+	aStrength := groundStrength(a.cs, landed)
+	dStrength := groundStrength(d.cs, availableSol(d.cs))
+	if !d.cs.defending {
+		dStrength = dStrength / 2
+	}
+	aKills, dKills := aStrength/groundLethality, dStrength/groundLethality
+
+	// attacker losses come out of the landing party
+	if dKills > landed {
+		dKills = landed
+	}
+	a.cs.sol.operational -= dKills
+	a.soldiersLost, a.landed = a.soldiersLost+dKills, landed-dKills
+
+	// defender losses come out of the soldiers and then the population
+	soldiers := aKills
+	if availableSol(d.cs) < soldiers {
+		soldiers = availableSol(d.cs)
+	}
+	d.cs.sol.operational -= soldiers
+	d.soldiersLost += soldiers
+	if civilians := aKills - soldiers; civilians > 0 {
+		if totalPop(d.cs) < civilians {
+			civilians = totalPop(d.cs)
+		}
+		killProportionally(d.cs, civilians)
+		d.deaths += civilians
+	}
+
+	return aKills != 0 || dKills != 0
+}
+
+// groundStrength is one point per soldier plus the tech level of the
+// assault weapon carried by each armed soldier plus the tech level of
+// each military robot.
+func groundStrength(cs *CorS, soldiers int) int {
+	strength, unarmed := soldiers, soldiers
+	for _, u := range operationalUnits(cs, "assault-weapon") {
+		armed := poweredUnits(cs, u)
+		if unarmed < armed {
+			armed = unarmed
+		}
+		strength, unarmed = strength+armed*u.Unit.TechLevel, unarmed-armed
+	}
+	for _, u := range operationalUnits(cs, "military-robots") {
+		strength += poweredUnits(cs, u) * u.Unit.TechLevel
+	}
+	return strength
+}
+
+// landedSoldiers returns the number of soldiers that the assault craft can carry.
+func landedSoldiers(cs *CorS) int {
+	capacity := 0
+	for _, u := range operationalUnits(cs, "assault-craft") {
+		capacity += poweredUnits(cs, u) * assaultCraftCapacity * u.Unit.TechLevel
+	}
+	if soldiers := availableSol(cs); soldiers < capacity {
+		return soldiers
+	}
+	return capacity
+}
+
+// hullMass returns the mass of the hull units that have not been destroyed.
+func hullMass(cs *CorS) int {
+	mass := 0.0
+	for _, u := range cs.Hull {
+		mass += float64(u.operational.initial-u.operational.destroyed) * u.Unit.MassPerUnit
+	}
+	return int(math.Ceil(mass))
+}
+
+// operationalUnits returns the hull and inventory units of the given kind
+// that are operational.
+func operationalUnits(cs *CorS, kind string) (units []*InventoryUnit) {
+	for _, u := range cs.Hull {
+		if u.Unit.Kind == kind && u.operational.available() > 0 {
+			units = append(units, u)
+		}
+	}
+	for _, u := range cs.Inventory {
+		if u.Unit.Kind == kind && u.operational.available() > 0 {
+			units = append(units, u)
+		}
+	}
+	return units
+}
+
+// poweredUnits returns the number of units that can be fueled for a combat round
+// and deducts the fuel used.
+func poweredUnits(cs *CorS, u *InventoryUnit) int {
+	qty := u.operational.available()
+	if isZero(u.Unit.FuelPerUnitPerCombatRound) {
+		return qty
+	}
+	if limit := int(float64(availableFuel(cs)) / u.Unit.FuelPerUnitPerCombatRound); limit < qty {
+		qty = limit
+	}
+	if qty < 0 {
+		qty = 0
+	}
+	burnFuel(cs, u.Unit.fuelUsedInCombat(qty))
+	return qty
+}
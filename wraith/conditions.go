@@ -0,0 +1,143 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+import (
+	"fmt"
+)
+
+// Condition compares the quantity of a unit in the inventory of a colony or ship to a number.
+// An order with a condition only runs when the comparison is true at the time its phase runs.
+type Condition struct {
+	CorS     string // id of ship or colony whose inventory is tested
+	Unit     string // unit to count
+	Op       string // <, <=, =, ==, !=, >=, or >
+	Quantity int
+}
+
+func (c *Condition) String() string {
+	return fmt.Sprintf("if %s %s %s %d", c.CorS, c.Unit, c.Op, c.Quantity)
+}
+
+// holds returns true if the comparison is true for the units in inventory that haven't been used this turn.
+func (c *Condition) holds(e *Engine) (bool, error) {
+	cs, ok := e.findColony(c.CorS)
+	if !ok {
+		if cs, ok = e.findShip(c.CorS); !ok {
+			return false, fmt.Errorf("no such colony or ship %q", c.CorS)
+		}
+	}
+	u, ok := unitFromString(e, c.Unit)
+	if !ok {
+		return false, fmt.Errorf("no such unit %q", c.Unit)
+	}
+	qty := availableUnits(cs, u)
+	switch c.Op {
+	case "<":
+		return qty < c.Quantity, nil
+	case "<=":
+		return qty <= c.Quantity, nil
+	case "=", "==":
+		return qty == c.Quantity, nil
+	case "!=":
+		return qty != c.Quantity, nil
+	case ">=":
+		return qty >= c.Quantity, nil
+	case ">":
+		return qty > c.Quantity, nil
+	}
+	return false, fmt.Errorf("no such comparison %q", c.Op)
+}
+
+// AddConditional adds all the orders in src to the player's orders.
+// The orders will only run if the condition holds when their phase runs.
+func (po *PhaseOrders) AddConditional(src *PhaseOrders, c *Condition) {
+	if po.Conditions == nil {
+		po.Conditions = make(map[interface{}]*Condition)
+	}
+	for _, o := range src.Combat {
+		po.Combat, po.Conditions[o] = append(po.Combat, o), c
+	}
+	for _, o := range src.SetUp {
+		po.SetUp, po.Conditions[o] = append(po.SetUp, o), c
+	}
+	for _, o := range src.Disassembly {
+		po.Disassembly, po.Conditions[o] = append(po.Disassembly, o), c
+	}
+	for _, o := range src.Retool {
+		po.Retool, po.Conditions[o] = append(po.Retool, o), c
+	}
+	for _, o := range src.Transfer {
+		po.Transfer, po.Conditions[o] = append(po.Transfer, o), c
+	}
+	for _, o := range src.Assembly {
+		po.Assembly, po.Conditions[o] = append(po.Assembly, o), c
+	}
+	for _, o := range src.Build {
+		po.Build, po.Conditions[o] = append(po.Build, o), c
+	}
+	for _, o := range src.Trade {
+		po.Trade, po.Conditions[o] = append(po.Trade, o), c
+	}
+	for _, o := range src.Survey {
+		po.Survey, po.Conditions[o] = append(po.Survey, o), c
+	}
+	for _, o := range src.Espionage {
+		po.Espionage, po.Conditions[o] = append(po.Espionage, o), c
+	}
+	for _, o := range src.Movement {
+		po.Movement, po.Conditions[o] = append(po.Movement, o), c
+	}
+	for _, o := range src.Draft {
+		po.Draft, po.Conditions[o] = append(po.Draft, o), c
+	}
+	for _, o := range src.Pay {
+		po.Pay, po.Conditions[o] = append(po.Pay, o), c
+	}
+	for _, o := range src.Ration {
+		po.Ration, po.Conditions[o] = append(po.Ration, o), c
+	}
+	for _, o := range src.Research {
+		po.Research, po.Conditions[o] = append(po.Research, o), c
+	}
+	for _, o := range src.Control {
+		po.Control, po.Conditions[o] = append(po.Control, o), c
+	}
+	for _, o := range src.Maintain {
+		po.Maintain, po.Conditions[o] = append(po.Maintain, o), c
+	}
+}
+
+// skip returns true if the order has a condition that doesn't hold.
+// Conditions that can't be tested are logged and the order is skipped.
+func (po *PhaseOrders) skip(e *Engine, order interface{}) bool {
+	c, ok := po.Conditions[order]
+	if !ok {
+		return false
+	}
+	holds, err := c.holds(e)
+	if err != nil {
+		po.Player.Log("  %s: %v: order skipped\n", c.String(), err)
+		return true
+	} else if !holds {
+		po.Player.Log("  %s: condition not met: order skipped\n", c.String())
+		return true
+	}
+	return false
+}
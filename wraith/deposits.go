@@ -0,0 +1,106 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+import (
+	"math/rand"
+	"sort"
+)
+
+const (
+	// depletedYieldFactor is the share of a deposit's yield that remains when its reserves are nearly exhausted.
+	// yield falls linearly from the full yield to this as the reserves fall.
+	depletedYieldFactor = 0.5
+	// discoveryPct is the chance that surveying a planet turns up a new deposit.
+	discoveryPct = 0.05
+)
+
+// depositYield returns the current yield of the deposit.
+// Yield drops as the reserves are mined out.
+func depositYield(d *Deposit) float64 {
+	if d.InitialQty <= 0 || d.RemainingQty <= 0 {
+		return d.YieldPct * depletedYieldFactor
+	}
+	remaining := float64(d.RemainingQty) / float64(d.InitialQty)
+	if remaining > 1 {
+		remaining = 1
+	}
+	return d.YieldPct * (depletedYieldFactor + (1-depletedYieldFactor)*remaining)
+}
+
+// discoverDeposit gives a survey of the planet a chance to find a new deposit.
+// The roll is seeded from the game, turn, and planet so that re-running a turn finds the same deposits.
+// Only the first survey of a planet in a turn gets a roll.
+// Returns the new deposit or nil if nothing was found.
+func discoverDeposit(e *Engine, planet *Planet) *Deposit {
+	if planet == nil || planet.Kind == "empty" || planet.prospected {
+		return nil
+	}
+	planet.prospected = true
+	seed := int64(e.Game.Id)<<40 ^ int64(e.Game.Turn.Year*4+e.Game.Turn.Quarter)<<20 ^ int64(planet.Id)
+	r := rand.New(rand.NewSource(seed))
+	if r.Float64() >= discoveryPct {
+		return nil
+	}
+
+	// the odds of each product match the odds used when the planets were generated,
+	// but new deposits are smaller than the original finds.
+	var kind string
+	var yield float64
+	var qty int
+	switch n := r.Intn(21); {
+	case n <= 10:
+		kind, yield, qty = "metallics", 0.75+float64(r.Intn(25))/100, (r.Intn(10)+1)*1_000_000
+	case n <= 17:
+		kind, yield, qty = "non-metallics", 0.50+float64(r.Intn(25))/100, (r.Intn(10)+1)*1_000_000
+	case n <= 19:
+		kind, yield, qty = "fuel", 0.10+float64(r.Intn(35))/100, (r.Intn(10)+1)*1_000_000
+	default:
+		kind, yield, qty = "gold", 0.01+float64(r.Intn(5))/100, (r.Intn(10)+1)*100_000
+	}
+	var product *Unit
+	for _, u := range e.Units {
+		if u.Kind == kind && (product == nil || u.Id < product.Id) {
+			product = u
+		}
+	}
+	if product == nil {
+		return nil
+	}
+
+	no := 0
+	for _, d := range planet.Deposits {
+		if d.No > no {
+			no = d.No
+		}
+	}
+	d := &Deposit{
+		Id:           e.NextSeq(),
+		No:           no + 1,
+		Product:      product,
+		InitialQty:   qty,
+		RemainingQty: qty,
+		YieldPct:     yield,
+		Planet:       planet,
+	}
+	e.Deposits[d.Id] = d
+	planet.Deposits = append(planet.Deposits, d)
+	sort.Sort(planet.Deposits)
+	return d
+}
@@ -0,0 +1,142 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+import "fmt"
+
+// draftRate is the fraction of the unemployed population that a colony
+// or ship can draft in a single turn.
+const draftRate = 0.1
+
+// ExecuteDraftPhase runs all the orders in the draft phase.
+// After the orders are applied, every colony and ship advances its trainees by one turn.
+func (e *Engine) ExecuteDraftPhase(pos []*PhaseOrders) (errs []error) {
+	for _, o := range pos {
+		o.Player.Log("\n\nDraft -----------------------------------------------------------\n")
+		for _, order := range o.Draft {
+			if o.skip(e, order) {
+				continue
+			}
+			if err := order.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	for _, cs := range e.sortedCorS() {
+		graduateTrainees(cs)
+	}
+	return errs
+}
+
+// Execute drafts unemployed population into training for a new class.
+// Construction crews take two units of unemployed population per crew.
+// The draft is limited to a fraction of the unemployed population each turn.
+// Will fail if the colony or ship is not controlled by the player.
+func (o *DraftOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	p.Log("  draft %s: %d %s\n", o.CorS, o.Quantity, o.Class)
+	if o.Quantity <= 0 {
+		p.Log("        %s: nothing to do\n", o.CorS)
+		return nil
+	}
+	cs, err := findControlled(e, p, "draft", o.CorS)
+	if err != nil {
+		return err
+	}
+
+	var skills Skills
+	if p.MemberOf != nil {
+		skills = p.MemberOf.Skills
+	}
+	turns, unitsPerTrainee := trainingTurns(o.Class, skills), 1
+	switch o.Class {
+	case "professional", "soldier", "unskilled":
+	case "construction-crew":
+		unitsPerTrainee = 2
+	default:
+		p.Log("        %s: no such population class %q\n", o.CorS, o.Class)
+		return fmt.Errorf("no such population class %q", o.Class)
+	}
+
+	// limit the draft to the per-turn cap and the unemployed available
+	limit := int(draftRate*float64(cs.Population.UnemployedQty)) - cs.drafted
+	if available := availableUem(cs); available < limit {
+		limit = available
+	}
+	qty := o.Quantity
+	if limit < qty*unitsPerTrainee {
+		qty = limit / unitsPerTrainee
+	}
+	cs.Log("        %s: requested %13d  drafted %13d\n", o.CorS, o.Quantity, qty)
+	if qty <= 0 {
+		return nil
+	}
+
+	cs.uem.operational -= qty * unitsPerTrainee
+	cs.drafted += qty * unitsPerTrainee
+	cs.Population.Training = append(cs.Population.Training, &Trainees{Class: o.Class, Qty: qty, TurnsLeft: turns})
+
+	return nil
+}
+
+// graduateTrainees moves trainees that have finished training into their new class.
+// They will not be available until after the bookkeeping phase.
+func graduateTrainees(cs *CorS) {
+	var training []*Trainees
+	for _, t := range cs.Population.Training {
+		if t.TurnsLeft--; t.TurnsLeft > 0 {
+			training = append(training, t)
+			continue
+		}
+		switch t.Class {
+		case "professional":
+			cs.pro.create(t.Qty)
+		case "soldier":
+			cs.sol.create(t.Qty)
+		case "unskilled":
+			cs.uns.create(t.Qty)
+		case "construction-crew":
+			cs.cons.create(t.Qty)
+		}
+		cs.Log("  %s: %d %s completed training\n", cs.HullId, t.Qty, t.Class)
+	}
+	cs.Population.Training = training
+}
+
+// trainingTurns returns the number of turns needed to train a class.
+// Unskilled workers train in a single turn. Soldiers take two turns, less
+// the nation's military skill. Professionals take four turns, less the
+// bureaucracy skill, and construction crews four, less the manufacturing skill.
+// Training always takes at least one turn.
+func trainingTurns(class string, skills Skills) (turns int) {
+	switch class {
+	case "soldier":
+		turns = 2 - skills.Military
+	case "professional":
+		turns = 4 - skills.Bureaucracy
+	case "construction-crew":
+		turns = 4 - skills.Manufacturing
+	}
+	if turns < 1 {
+		turns = 1
+	}
+	return turns
+}
@@ -0,0 +1,268 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// counterIntelFactor is how much more effective a spy team assigned to counter-intelligence
+	// is at catching enemy spies than an idle spy team
+	counterIntelFactor = 2
+	// soldiersPerSpyTeam is the number of soldiers that provide the same security as one spy team
+	soldiersPerSpyTeam = 100
+	// incitePct is the increase in the rebel percentage for each spy team that isn't caught
+	incitePct = 0.005
+	// sabotageQty is the number of units of work in progress destroyed by each spy team that isn't caught
+	sabotageQty = 100
+)
+
+// ExecuteEspionagePhase runs all the orders in the espionage phase.
+// Counter-intelligence orders are processed first so that they protect
+// against every operation run this turn.
+func (e *Engine) ExecuteEspionagePhase(pos []*PhaseOrders) (errs []error) {
+	for _, cs := range e.sortedCorS() {
+		cs.counterIntel = 0
+	}
+	for _, o := range pos {
+		o.Player.Log("\n\nEspionage -------------------------------------------------------\n")
+		for _, order := range o.Espionage {
+			if o.skip(e, order) {
+				continue
+			}
+			if err := order.CounterIntel.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	for _, o := range pos {
+		for _, order := range o.Espionage {
+			if o.skip(e, order) {
+				continue
+			}
+			if err := order.GatherIntel.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+			if err := order.Incite.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+			if err := order.Sabotage.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// Execute assigns spy teams to protect the colony or ship from enemy spies.
+// Will fail if the colony or ship is not controlled by the player.
+func (o *CounterIntelOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	p.Log("  counter-intel %s: %13d spy-team\n", o.CorS, o.Quantity)
+	cs, err := findControlled(e, p, "counter-intel", o.CorS)
+	if err != nil {
+		return err
+	}
+	qty := o.Quantity
+	if availableSpy(cs) < qty {
+		qty = availableSpy(cs)
+	}
+	if qty <= 0 {
+		p.Log("                %s: no spy teams available\n", o.CorS)
+		return fmt.Errorf("%s: no spy teams available", o.CorS)
+	}
+	cs.spy.allocated += qty
+	cs.counterIntel += qty
+	p.Log("                %s: assigned %d spy teams to counter-intelligence\n", o.CorS, qty)
+	return nil
+}
+
+// Execute sends spy teams to report on the population, hull, and factory groups of the target.
+// Will fail if the colony or ship is not controlled by the player or the target is not in the same system.
+func (o *GatherIntelOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	p.Log("  gather-intel %s: %13d spy-team %s\n", o.CorS, o.Quantity, o.Target)
+	spy, target, err := findSpyTarget(e, p, "gather-intel", o.CorS, o.Target)
+	if err != nil {
+		return err
+	}
+	survivors, ok := infiltrate(p, "gather-intel", spy, target, o.Quantity)
+	if !ok {
+		return fmt.Errorf("%s: no spy teams available", o.CorS)
+	} else if survivors == 0 {
+		return nil
+	}
+
+	pop := target.Population
+	lines := []string{
+		fmt.Sprintf("%s: gather-intel %s: population: %d PRO  %d SOL  %d UNS  %d UEM  %d CON  %d SPY  rebels %.1f%%",
+			spy.HullId, target.HullId, pop.ProfessionalQty, pop.SoldierQty, pop.UnskilledQty, pop.UnemployedQty,
+			pop.ConstructionCrewQty, pop.SpyTeamQty, pop.RebelPct*100),
+	}
+	for _, u := range target.Hull {
+		lines = append(lines, fmt.Sprintf("%s: gather-intel %s: hull: %-7s %13d", spy.HullId, target.HullId, u.Unit.Code, u.ActiveQty))
+	}
+	for _, group := range target.FactoryGroups {
+		lines = append(lines, fmt.Sprintf("%s: gather-intel %s: factory group FG%d: producing %s", spy.HullId, target.HullId, group.No, group.Product.Code))
+	}
+	p.EspionageReport = append(p.EspionageReport, lines...)
+	p.Log("               %s: gathered intel on %s\n", o.CorS, o.Target)
+
+	return nil
+}
+
+// Execute sends spy teams to stir up unrest in the target.
+// Each team that isn't caught raises the rebel percentage of the target.
+// Will fail if the colony or ship is not controlled by the player or the target is not in the same system.
+func (o *InciteOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	p.Log("  incite %s: %13d spy-team %s\n", o.CorS, o.Quantity, o.Target)
+	spy, target, err := findSpyTarget(e, p, "incite", o.CorS, o.Target)
+	if err != nil {
+		return err
+	}
+	survivors, ok := infiltrate(p, "incite", spy, target, o.Quantity)
+	if !ok {
+		return fmt.Errorf("%s: no spy teams available", o.CorS)
+	} else if survivors == 0 {
+		return nil
+	}
+
+	pct := float64(survivors) * incitePct
+	if target.Population.RebelPct+pct > 1 {
+		pct = 1 - target.Population.RebelPct
+	}
+	target.Population.RebelPct += pct
+	target.incitedBy = p
+	p.EspionageReport = append(p.EspionageReport, fmt.Sprintf("%s: incite %s: rebels increased by %.1f%%", spy.HullId, target.HullId, pct*100))
+	if target.ControlledBy != nil {
+		target.ControlledBy.EspionageReport = append(target.ControlledBy.EspionageReport, fmt.Sprintf("%s: agitators increased rebels by %.1f%%", target.HullId, pct*100))
+	}
+	p.Log("         %s: incited %s, rebels increased by %.1f%%\n", o.CorS, o.Target, pct*100)
+
+	return nil
+}
+
+// Execute sends spy teams to destroy work in progress in one of the target's factory groups.
+// The latest stages are destroyed first.
+// Will fail if the colony or ship is not controlled by the player, the target is not in the same system,
+// or the target doesn't have the factory group.
+func (o *SabotageOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	p.Log("  sabotage %s: %13d spy-team %s %s\n", o.CorS, o.Quantity, o.Target, o.Group)
+	spy, target, err := findSpyTarget(e, p, "sabotage", o.CorS, o.Target)
+	if err != nil {
+		return err
+	}
+	var fg *FactoryGroup
+	for _, group := range target.FactoryGroups {
+		if o.Group == fmt.Sprintf("FG%d", group.No) {
+			fg = group
+			break
+		}
+	}
+	if fg == nil {
+		p.Log("           %s: no such factory group %q\n", o.CorS, o.Group)
+		return fmt.Errorf("no such factory group %q", o.Group)
+	}
+	survivors, ok := infiltrate(p, "sabotage", spy, target, o.Quantity)
+	if !ok {
+		return fmt.Errorf("%s: no spy teams available", o.CorS)
+	} else if survivors == 0 {
+		return nil
+	}
+
+	destroyed, remaining := 0, survivors*sabotageQty
+	for stage := len(fg.StageQty) - 2; stage >= 0 && remaining > 0; stage-- {
+		qty := fg.StageQty[stage]
+		if remaining < qty {
+			qty = remaining
+		}
+		fg.StageQty[stage] -= qty
+		destroyed, remaining = destroyed+qty, remaining-qty
+	}
+	p.EspionageReport = append(p.EspionageReport, fmt.Sprintf("%s: sabotage %s: destroyed %d %s in FG%d", spy.HullId, target.HullId, destroyed, fg.Product.Code, fg.No))
+	if target.ControlledBy != nil && destroyed > 0 {
+		target.ControlledBy.EspionageReport = append(target.ControlledBy.EspionageReport, fmt.Sprintf("%s: saboteurs destroyed %d %s in FG%d", target.HullId, destroyed, fg.Product.Code, fg.No))
+	}
+	p.Log("           %s: destroyed %d %s in %s FG%d\n", o.CorS, destroyed, fg.Product.Code, o.Target, fg.No)
+
+	return nil
+}
+
+// findSpyTarget returns the colony or ship sending the spy teams and the target.
+func findSpyTarget(e *Engine, p *Player, verb, id, targetId string) (spy, target *CorS, err error) {
+	if spy, err = findControlled(e, p, verb, id); err != nil {
+		return nil, nil, err
+	}
+	// the target must be in the same system
+	target, ok := e.findColony(targetId)
+	if !ok {
+		target, ok = e.findShip(targetId)
+	}
+	if !ok || spy.Planet == nil || target.Planet == nil || target.Planet.System != spy.Planet.System {
+		p.Log("  %s %s: no such target %s\n", verb, id, targetId)
+		return nil, nil, fmt.Errorf("no such target %q", targetId)
+	} else if target == spy || target.ControlledBy == p {
+		p.Log("  %s %s: can not target own colony or ship %s\n", verb, id, targetId)
+		return nil, nil, fmt.Errorf("%s: can not target own colony or ship", id)
+	}
+	return spy, target, nil
+}
+
+// infiltrate commits spy teams against the target's security.
+// Security comes from the target's counter-intelligence teams, idle spy teams, and soldiers.
+// Teams are caught in proportion to the target's share of the combined strength.
+// Caught teams are lost and the target's controller is told who sent them.
+// Returns the number of teams that weren't caught and false if there were no teams to commit.
+func infiltrate(p *Player, verb string, spy, target *CorS, qty int) (int, bool) {
+	if availableSpy(spy) < qty {
+		qty = availableSpy(spy)
+	}
+	if qty <= 0 {
+		p.Log("  %s %s: no spy teams available\n", verb, spy.HullId)
+		return 0, false
+	}
+	spy.spy.allocated += qty
+
+	security := counterIntelFactor*target.counterIntel + availableSpy(target) + target.sol.operational/soldiersPerSpyTeam
+	caught := int(math.Round(float64(qty) * float64(security) / float64(qty+security)))
+	spy.spy.destroyed += caught
+	p.Log("  %s %s: %d spy teams committed, %d caught\n", verb, spy.HullId, qty, caught)
+
+	if caught != 0 {
+		p.EspionageReport = append(p.EspionageReport, fmt.Sprintf("%s: %s %s: %d of %d spy teams caught", spy.HullId, verb, target.HullId, caught, qty))
+		if target.ControlledBy != nil {
+			target.ControlledBy.EspionageReport = append(target.ControlledBy.EspionageReport,
+				fmt.Sprintf("%s: caught %d spy teams from %s attempting to %s", target.HullId, caught, spy.HullId, verb))
+		}
+	}
+
+	return qty - caught, true
+}
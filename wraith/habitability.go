@@ -0,0 +1,46 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+const (
+	// populationPerHabitabilityNo is the number of population units that
+	// each point of habitability supports in an open colony.
+	populationPerHabitabilityNo = 1_000_000
+	// minOpenHabitabilityNo is the lowest habitability that can support an open colony.
+	// planets below this must be settled with enclosed colonies.
+	minOpenHabitabilityNo = 5
+)
+
+// naturalCapacity returns the population that the planet supports without life support.
+// Only open colonies on viable planets have a natural capacity.
+func naturalCapacity(cs *CorS) int {
+	if !(cs.Kind == "open" || cs.Kind == "surface") || !openColonyViable(cs.Planet) {
+		return 0
+	}
+	return cs.Planet.HabitabilityNo * populationPerHabitabilityNo
+}
+
+// openColonyViable returns true if the planet can support an open colony.
+// Gas giants and low-habitability planets require enclosed colonies.
+func openColonyViable(planet *Planet) bool {
+	if planet == nil || planet.Kind != "terrestrial" {
+		return false
+	}
+	return planet.HabitabilityNo >= minOpenHabitabilityNo
+}
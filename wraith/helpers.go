@@ -92,6 +92,12 @@ func factoryProduction(cs *CorS, pos []*PhaseOrders) {
 	cs.Log("  %13d / %13d / %13d / %13d NMTL\n", nmtl.allocated, nmtl.available, nmtl.activeQty, nmtl.ActiveQty)
 
 	for _, group := range cs.FactoryGroups {
+		if group.IdleTurns > 0 {
+			group.IdleTurns--
+			cs.Log("\n  Group %2d: %-20s  idle while retooling\n", group.No, group.Product.Name)
+			continue
+		}
+
 		unitsProduced := 0
 
 		factoriesInGroup := 0
@@ -130,10 +136,10 @@ func factoryProduction(cs *CorS, pos []*PhaseOrders) {
 			cs.Log("          : professional       %13d  %13d  %13d\n", requested, availablePro(cs), factoriesAllocated)
 
 			requested = factoriesAllocated * proFactor * 3
-			if requested < availableUns(cs) {
-				factoriesAllocated = requested / (proFactor * 3)
+			if availableUnskilled(cs) < requested {
+				factoriesAllocated = availableUnskilled(cs) / (proFactor * 3)
 			}
-			cs.Log("          : unskilled workers  %13d  %13d  %13d\n", requested, availableUns(cs), factoriesAllocated)
+			cs.Log("          : unskilled workers  %13d  %13d  %13d\n", requested, availableUnskilled(cs), factoriesAllocated)
 
 			requested = int(math.Ceil(float64(factoriesAllocated) * moe.Unit.FuelPerUnitPerTurn))
 			if requested != 0 {
@@ -165,18 +171,19 @@ func factoryProduction(cs *CorS, pos []*PhaseOrders) {
 			// allocate fuel
 			moe.fuel.needed = moe.Unit.fuelUsed(moe.ActiveQty)
 			moe.fuel.allocated = moe.Unit.fuelUsed(int(math.Ceil(float64(factoriesAllocated) / 2)))
-			cs.fuel.operational -= moe.fuel.allocated
+			burnFuel(cs, moe.fuel.allocated)
 
 			// allocate professional labor
 			moe.pro.needed = moe.ActiveQty
 			moe.pro.allocated = factoriesAllocated * proFactor
 			cs.pro.allocated += moe.pro.allocated
 
-			// allocate unskilled labor
-			// TODO: allow automation units to replace unskilled labor
+			// allocate unskilled labor, using automation units first
 			moe.uns.needed = 3 * moe.pro.needed
 			moe.uns.allocated = 3 * factoriesAllocated * proFactor
-			cs.uns.allocated += moe.uns.allocated
+			if aut := allocateUnskilled(cs, moe.uns.allocated); aut != 0 {
+				cs.Log("          : automation         %13d  replacing unskilled workers\n", aut)
+			}
 
 			allocateMetallics(cs, int(math.Ceil(float64(factoriesAllocated)/group.Product.MetsPerUnitPerTurn)))
 			allocateNonMetallics(cs, int(math.Ceil(float64(factoriesAllocated)/group.Product.NonMetsPerUnitPerTurn)))
@@ -185,6 +192,12 @@ func factoryProduction(cs *CorS, pos []*PhaseOrders) {
 			unitsProduced = output / 4
 		}
 
+		// unrest cuts production
+		if penalty := unrestPenalty(cs); penalty > 0 {
+			unitsProduced = int(float64(unitsProduced) * (1 - penalty))
+			cs.Log("          : unrest %6.2f%% cuts production by %6.2f%%\n", cs.Population.RebelPct*100, penalty*100)
+		}
+
 		// push the newly produced units through the pipeline
 		if group.StageQty[2] > unitsProduced {
 			group.StageQty[3] = unitsProduced
@@ -229,21 +242,24 @@ func farmProduction(cs *CorS, pos []*PhaseOrders) {
 		for _, moe := range group.Units {
 			unitsActive := maxCapacity(cs, moe)
 
-			// allocate fuel
-			moe.fuel.needed = moe.Unit.fuelUsed(moe.ActiveQty)
-			moe.fuel.allocated = moe.Unit.fuelUsed(unitsActive)
-			cs.fuel.operational -= moe.fuel.allocated
+			// allocate fuel. solar farms run on sunlight.
+			if !isSolarPowered(moe.Unit, cs) {
+				moe.fuel.needed = moe.Unit.fuelUsed(moe.ActiveQty)
+				moe.fuel.allocated = moe.Unit.fuelUsed(unitsActive)
+				burnFuel(cs, moe.fuel.allocated)
+			}
 
 			// allocate professional labor
 			moe.pro.needed = moe.ActiveQty
 			moe.pro.allocated = unitsActive
 			cs.pro.allocated += moe.pro.allocated
 
-			// allocate unskilled labor
-			// TODO: allow automation units to replace unskilled labor
+			// allocate unskilled labor, using automation units first
 			moe.uns.needed = 3 * moe.pro.needed
 			moe.uns.allocated = 3 * unitsActive
-			cs.uns.allocated += moe.uns.allocated
+			if aut := allocateUnskilled(cs, moe.uns.allocated); aut != 0 {
+				cs.Log("  Group %2d: automation %8d replacing unskilled workers\n", group.No, aut)
+			}
 
 			cs.Log("  Group %2d: fuel %8d / %8d: pro %8d / %8d: uns %8d / %8d\n",
 				group.No, moe.fuel.allocated, moe.fuel.needed, moe.pro.needed, moe.pro.allocated, moe.uns.allocated, moe.uns.allocated)
@@ -254,10 +270,20 @@ func farmProduction(cs *CorS, pos []*PhaseOrders) {
 			} else {
 				unitsProduced = unitsActive * 20 * moe.Unit.TechLevel
 			}
+			// solar farms produce less in weaker sunlight
+			if isSolarPowered(moe.Unit, cs) {
+				unitsProduced = int(float64(unitsProduced) * solarIntensity(cs))
+			}
 			// convert from units per year to units per turn
 			unitsProduced = unitsProduced / 4
 		}
 
+		// unrest cuts production
+		if penalty := unrestPenalty(cs); penalty > 0 {
+			unitsProduced = int(float64(unitsProduced) * (1 - penalty))
+			cs.Log("          : unrest %6.2f%% cuts production by %6.2f%%\n", cs.Population.RebelPct*100, penalty*100)
+		}
+
 		// push the newly produced units through the pipeline
 		if group.StageQty[2] > unitsProduced {
 			group.StageQty[3] = unitsProduced
@@ -291,11 +317,47 @@ func farmProduction(cs *CorS, pos []*PhaseOrders) {
 	}
 }
 
+// consume removes up to qty units of the given kind from inventory,
+// using stowed units before operational units.
+// it returns the number of units actually consumed.
+func consume(cs *CorS, kind string, qty int) (consumed int) {
+	for _, u := range cs.Inventory {
+		if consumed == qty {
+			break
+		} else if u.Unit.Kind != kind {
+			continue
+		}
+		consumed += u.stowed.remove(qty - consumed)
+		consumed += u.operational.remove(qty - consumed)
+	}
+	return consumed
+}
+
 func (e *Engine) findColony(id string) (*CorS, bool) {
 	c, ok := e.Colonies[id]
 	return c, ok
 }
 
+// findPlanet returns the planet in the given orbit of a star.
+func (e *Engine) findPlanet(coords Coordinates, star string, orbitNo int) (*Planet, bool) {
+	for _, system := range e.Systems {
+		if system.Coords != coords {
+			continue
+		}
+		for _, s := range system.Stars {
+			if s.Sequence != star {
+				continue
+			}
+			for _, planet := range s.Planets {
+				if planet.OrbitNo == orbitNo {
+					return planet, true
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
 func (e *Engine) findShip(id string) (*CorS, bool) {
 	s, ok := e.Ships[id]
 	return s, ok
@@ -303,13 +365,7 @@ func (e *Engine) findShip(id string) (*CorS, bool) {
 
 func fuelInitialization(cs *CorS, pos []*PhaseOrders) {
 	cs.Log("Colony: %-10s   Kind: %-10s  Name: %s\n", cs.HullId, cs.Kind, cs.Name)
-	for _, u := range cs.Inventory {
-		if u.Unit.Kind != "fuel" {
-			continue
-		}
-		cs.fuel.operational += u.ActiveQty + u.StowedQty
-	}
-	cs.Log("  %13d FUEL available for use\n\n", cs.fuel.operational)
+	cs.Log("  %13d FUEL available for use\n\n", fuelOnHand(cs))
 }
 
 func indexOf(s string, sl []string) int {
@@ -321,10 +377,12 @@ func indexOf(s string, sl []string) int {
 	return -1
 }
 
+// isSolarPowered returns true if the unit runs on sunlight instead of fuel.
+// the output of solar powered units is scaled by the solar intensity.
 func isSolarPowered(u *Unit, cs *CorS) bool {
 	switch u.Kind {
 	case "farm":
-		return cs.Planet.OrbitNo <= 5 && cs.Kind == "orbital" && ("FRM-2" <= u.Code && u.Code <= "FRM-5")
+		return cs.Kind == "orbital" && solarIntensity(cs) > 0 && ("FRM-2" <= u.Code && u.Code <= "FRM-5")
 	}
 	return false
 }
@@ -395,15 +453,18 @@ func killProportionally(cs *CorS, n int) {
 func laborInitialization(cs *CorS, pos []*PhaseOrders) {
 	cs.Log("Colony: %-10s   Kind: %-10s  Name: %s\n", cs.HullId, cs.Kind, cs.Name)
 	cs.Log("  PRO %13d  SOL %13d  UNS %13d  FUEL %13d\n  UEM %13d  CON %13d  SPY %13d\n",
-		cs.Population.ProfessionalQty, cs.Population.SoldierQty, cs.Population.UnskilledQty, cs.fuel.operational,
+		cs.Population.ProfessionalQty, cs.Population.SoldierQty, cs.Population.UnskilledQty, availableFuel(cs),
 		cs.Population.UnemployedQty, cs.Population.ConstructionCrewQty, cs.Population.SpyTeamQty)
 
+	cs.laborLoaded = true
 	cs.pro.operational = cs.Population.ProfessionalQty
 	cs.sol.operational = cs.Population.SoldierQty
 	cs.uns.operational = cs.Population.UnskilledQty
 	cs.uem.operational = cs.Population.UnemployedQty
 
-	// TODO: let automation units replace unskilled workers
+	// automation units replace unskilled workers
+	automationInitialization(cs)
+
 	cs.cons.operational = cs.Population.ConstructionCrewQty
 	if cs.cons.operational > 0 {
 		if availablePro(cs) < cs.cons.operational {
@@ -435,14 +496,14 @@ func laborInitialization(cs *CorS, pos []*PhaseOrders) {
 	return
 }
 
-// TODO: logic for factory groups efficiency, automation units, and construction crews
+// TODO: logic for factory groups efficiency and construction crews
 func maxCapacity(cs *CorS, u *InventoryUnit) int {
 	// assume maximum capacity
 	maxUnits := u.ActiveQty
 
 	// limit capacity based on available fuel
 	if !(isSolarPowered(u.Unit, cs) || isZero(u.Unit.FuelPerUnitPerTurn)) {
-		fuelCapacity := int(float64(cs.fuel.operational) / u.Unit.FuelPerUnitPerTurn)
+		fuelCapacity := int(float64(availableFuel(cs)) / u.Unit.FuelPerUnitPerTurn)
 		if maxUnits > fuelCapacity {
 			maxUnits = fuelCapacity
 		}
@@ -453,9 +514,9 @@ func maxCapacity(cs *CorS, u *InventoryUnit) int {
 		maxUnits = availablePro(cs)
 	}
 
-	// limit capacity based on available unskilled workers
-	if maxUnits > availableUns(cs)/3 {
-		maxUnits = availableUns(cs) / 3
+	// limit capacity based on available unskilled workers and automation units
+	if maxUnits > availableUnskilled(cs)/3 {
+		maxUnits = availableUnskilled(cs) / 3
 	}
 
 	return maxUnits
@@ -468,6 +529,22 @@ func mineProduction(cs *CorS, pos []*PhaseOrders) {
 		availableUem(cs), availableCon(cs), availableSpy(cs))
 
 	for _, group := range cs.MineGroups {
+		if group.IdleTurns > 0 {
+			group.IdleTurns--
+			cs.Log("  Group %2d: %-6s      idle while retooling\n", group.No, group.Deposit.Product.Code)
+			continue
+		}
+
+		yield := depositYield(group.Deposit)
+		if group.Deposit.RemainingQty <= 0 {
+			// exhausted deposits idle the mines, but the work in progress is still finished
+			group.StageQty[3] = int(math.Ceil(float64(group.StageQty[2]) * yield))
+			group.StageQty[2], group.StageQty[1], group.StageQty[0] = group.StageQty[1], group.StageQty[0], 0
+			cs.Log("  Group %2d: %-6s      idle: deposit %d is exhausted\n", group.No, group.Deposit.Product.Code, group.Deposit.No)
+			cs.Log("            75%%: %13d  finished: %13d %s\n", group.StageQty[2], group.StageQty[3], group.Deposit.Product.Code)
+			continue
+		}
+
 		unitsProduced := 0
 		moe := group.Unit
 		unitsActive := maxCapacity(cs, moe)
@@ -475,21 +552,22 @@ func mineProduction(cs *CorS, pos []*PhaseOrders) {
 		// allocate fuel
 		moe.fuel.needed = moe.Unit.fuelUsed(moe.ActiveQty)
 		moe.fuel.allocated = moe.Unit.fuelUsed(unitsActive)
-		cs.fuel.operational -= moe.fuel.allocated
+		burnFuel(cs, moe.fuel.allocated)
 
 		// allocate professional labor
 		moe.pro.needed = moe.ActiveQty
 		moe.pro.allocated = unitsActive
 		cs.pro.allocated += moe.pro.allocated
 
-		// allocate unskilled labor
-		// TODO: allow automation units to replace unskilled labor
+		// allocate unskilled labor, using automation units first
 		moe.uns.needed = 3 * moe.pro.needed
 		moe.uns.allocated = 3 * unitsActive
-		cs.uns.allocated += moe.uns.allocated
+		if aut := allocateUnskilled(cs, moe.uns.allocated); aut != 0 {
+			cs.Log("  Group %2d: automation %8d replacing unskilled workers\n", group.No, aut)
+		}
 
 		cs.Log("  Group %2d: %-6s      yield: %7.3f%%     reserves: %13d tonnes\n",
-			group.No, group.Deposit.Product.Code, 100*group.Deposit.YieldPct, group.Deposit.RemainingQty)
+			group.No, group.Deposit.Product.Code, 100*yield, group.Deposit.RemainingQty)
 		cs.Log("            fuel %8d / %8d: pro %8d / %8d: uns %8d / %8d\n",
 			moe.fuel.allocated, moe.fuel.needed, moe.pro.needed, moe.pro.allocated, moe.uns.allocated, moe.uns.allocated)
 
@@ -498,12 +576,23 @@ func mineProduction(cs *CorS, pos []*PhaseOrders) {
 		// convert from units per year to units per turn
 		unitsProduced = unitsProduced / 4
 
+		// unrest cuts production
+		if penalty := unrestPenalty(cs); penalty > 0 {
+			unitsProduced = int(float64(unitsProduced) * (1 - penalty))
+			cs.Log("          : unrest %6.2f%% cuts production by %6.2f%%\n", cs.Population.RebelPct*100, penalty*100)
+		}
+
+		// mines can't extract more than the deposit holds
+		if unitsProduced > group.Deposit.RemainingQty {
+			unitsProduced = group.Deposit.RemainingQty
+		}
+
 		// push the newly produced units through the pipeline
 		if group.StageQty[2] > unitsProduced {
-			group.StageQty[3] = int(math.Ceil(float64(unitsProduced) * group.Deposit.YieldPct))
+			group.StageQty[3] = int(math.Ceil(float64(unitsProduced) * yield))
 			group.StageQty[2] -= unitsProduced
 		} else {
-			group.StageQty[3] = int(math.Ceil(float64(group.StageQty[2]) * group.Deposit.YieldPct))
+			group.StageQty[3] = int(math.Ceil(float64(group.StageQty[2]) * yield))
 			group.StageQty[2] = 0
 		}
 		if group.StageQty[1] > unitsProduced {
@@ -522,6 +611,9 @@ func mineProduction(cs *CorS, pos []*PhaseOrders) {
 		}
 		group.Deposit.RemainingQty -= unitsProduced
 		group.StageQty[0] += unitsProduced
+		if group.Deposit.RemainingQty <= 0 {
+			cs.Log("  **** deposit %d (%s) is exhausted! group %d will be idled.\n", group.Deposit.No, group.Deposit.Product.Code, group.No)
+		}
 		cs.Log("            25%%: %13d       50%%: %13d\n", group.StageQty[0], group.StageQty[1])
 		cs.Log("            75%%: %13d  finished: %13d %s\n", group.StageQty[2], group.StageQty[3], group.Deposit.Product.Code)
 
@@ -531,14 +623,55 @@ func mineProduction(cs *CorS, pos []*PhaseOrders) {
 	}
 }
 
+// totalMass returns the mass of the hull and everything in inventory.
+func totalMass(cs *CorS) int {
+	mass := 0
+	for _, u := range cs.Hull {
+		mass += u.totalMass()
+	}
+	for _, u := range cs.Inventory {
+		mass += u.totalMass()
+	}
+	return mass
+}
 func totalPop(cs *CorS) int {
 	return cs.pro.operational + cs.sol.operational + cs.uns.operational + cs.uem.operational + 2*cs.cons.operational + 2*cs.spy.operational
 }
+func availableAut(cs *CorS) int {
+	return cs.aut.operational - cs.aut.allocated
+}
 func availableCon(cs *CorS) int {
 	return cs.cons.operational - cs.cons.allocated
 }
+
+// availableFuel returns the fuel and energy available for use.
 func availableFuel(cs *CorS) int {
-	return cs.fuel.operational - cs.fuel.allocated
+	return fuelOnHand(cs) + cs.pwr.operational - cs.pwr.allocated
+}
+
+// fuelOnHand returns the fuel in inventory that is available for use.
+func fuelOnHand(cs *CorS) (qty int) {
+	for _, u := range cs.Inventory {
+		if u.Unit.Kind == "fuel" {
+			qty += u.stowed.available() + u.operational.available()
+		}
+	}
+	return qty
+}
+
+// removeFuel burns fuel from inventory, stowed units first.
+// the fuel is booked as destroyed so that the bookkeeping phase drops it.
+// it returns the amount of fuel removed, which will be less than the
+// requested amount when there isn't enough fuel available.
+func removeFuel(cs *CorS, qty int) (removed int) {
+	for _, u := range cs.Inventory {
+		if u.Unit.Kind != "fuel" {
+			continue
+		}
+		removed += u.stowed.remove(qty - removed)
+		removed += u.operational.remove(qty - removed)
+	}
+	return removed
 }
 func availableMetallics(cs *CorS) int {
 	for _, u := range cs.Inventory {
@@ -45,6 +45,7 @@ type Engine struct {
 	Deposits        map[int]*Deposit
 	FactoryGroups   map[int]*FactoryGroup
 	FarmGroups      map[int]*FarmGroup
+	Market          MarketReports // results of the last trade phase
 	MineGroups      map[int]*MineGroup
 	Nations         map[int]*Nation
 	Planets         map[int]*Planet
@@ -55,6 +56,7 @@ type Engine struct {
 	Units           map[int]*Unit
 	UnitsFromString map[string]*Unit
 	Seq             int
+	floor           *tradingFloor // orders posted during the trade phase
 }
 
 func (e *Engine) NextSeq() int {
@@ -64,43 +66,63 @@ func (e *Engine) NextSeq() int {
 
 // CorS is a colony or ship
 type CorS struct {
-	Id                                  int     // unique identifier
-	Kind                                string  // orbital, ship, or surface
-	HullId                              string  // S or C + MSN
-	MSN                                 int     // manufacturer serial number; in game id for the colony or ship
-	BuiltBy                             *Nation // nation that originally built the colony or ship
-	Name                                string  // name of this colony or ship
-	TechLevel                           int     // tech level of this colony or ship
-	ControlledBy                        *Player // player that controls this colony or ship
-	Planet                              *Planet // planet the colony or ship is located at
-	Hull                                InventoryUnits
-	Inventory                           InventoryUnits
-	Population                          Population
-	Pay                                 Pay
-	Rations                             Rations
-	FactoryGroups                       FactoryGroups // list of the factory groups
-	FarmGroups                          FarmGroups    // list of the farm groups
-	MineGroups                          MineGroups    // list of the mine groups
-	fuel, pro, sol, uns, uem, cons, spy requisition
-	lifeSupportCapacity                 int
-	nonCombatDeaths                     int
+	Id                            int     // unique identifier
+	Kind                          string  // orbital, ship, or surface
+	HullId                        string  // S or C + MSN
+	MSN                           int     // manufacturer serial number; in game id for the colony or ship
+	BuiltBy                       *Nation // nation that originally built the colony or ship
+	Name                          string  // name of this colony or ship
+	TechLevel                     int     // tech level of this colony or ship
+	ControlledBy                  *Player // player that controls this colony or ship
+	Planet                        *Planet // planet the colony or ship is located at
+	Hull                          InventoryUnits
+	Inventory                     InventoryUnits
+	Population                    Population
+	Pay                           Pay
+	Rations                       Rations
+	FactoryGroups                 FactoryGroups // list of the factory groups
+	FarmGroups                    FarmGroups    // list of the farm groups
+	MineGroups                    MineGroups    // list of the mine groups
+	pro, sol, uns, uem, cons, spy requisition
+	aut                           requisition // unskilled workers replaced by automation units
+	pwr                           requisition // energy from power plants and solar arrays
+	lifeSupportCapacity           int
+	nonCombatDeaths               int
+	counterIntel                  int     // spy teams assigned to counter-intelligence this turn
+	defending                     bool    // true if ordered to defend this turn
+	drafted                       int     // unemployed drafted this turn
+	incitedBy                     *Player // player whose spies stirred up the rebels this turn
+	laborLoaded                   bool    // true once the labor pools are loaded from the population this turn
+	maintainFirst                 []*Unit // units to maintain before all others this turn
+	payShortfall                  float64 // share of pay that couldn't be met this turn
+	rationShortfall               float64 // share of rations that couldn't be met this turn
+	surveyed                      int     // orbits surveyed this turn
 }
 
 func (cs *CorS) InitializeInventory() {
 	for _, u := range cs.Hull {
 		u.operational.initial, u.operational.allocated, u.operational.created, u.operational.destroyed = u.ActiveQty, 0, 0, 0
-		u.stowed.initial, u.stowed.allocated, u.operational.created, u.stowed.destroyed = u.StowedQty, 0, 0, 0
+		u.stowed.initial, u.stowed.allocated, u.stowed.created, u.stowed.destroyed = u.StowedQty, 0, 0, 0
 	}
 	for _, u := range cs.Inventory {
-		u.operational.initial, u.operational.allocated, u.stowed.created, u.operational.destroyed = u.ActiveQty, 0, 0, 0
+		u.operational.initial, u.operational.allocated, u.operational.created, u.operational.destroyed = u.ActiveQty, 0, 0, 0
 		u.stowed.initial, u.stowed.allocated, u.stowed.created, u.stowed.destroyed = u.StowedQty, 0, 0, 0
 	}
 }
 
-func (cs *CorS) lifeSupportCheck() {
-	if !(cs.Kind == "enclosed" || cs.Kind == "orbital" || cs.Kind == "ship") {
-		return
+// updateInventory books the units created or destroyed during the turn into the inventory.
+func (cs *CorS) updateInventory() {
+	for _, u := range cs.Hull {
+		u.ActiveQty += u.operational.created - u.operational.destroyed
+		u.StowedQty += u.stowed.created - u.stowed.destroyed
+	}
+	for _, u := range cs.Inventory {
+		u.ActiveQty += u.operational.created - u.operational.destroyed
+		u.StowedQty += u.stowed.created - u.stowed.destroyed
 	}
+}
+
+func (cs *CorS) lifeSupportCheck() {
 	var playerName string
 	if cs.ControlledBy != nil {
 		playerName = cs.ControlledBy.Name
@@ -117,9 +139,11 @@ func (cs *CorS) lifeSupportCheck() {
 }
 
 func (cs *CorS) lifeSupportInitialization(pos []*PhaseOrders) {
-	cs.lifeSupportCapacity = 0
-	if !(cs.Kind == "enclosed" || cs.Kind == "orbital" || cs.Kind == "ship") {
-		return
+	// open colonies start with the population that the planet supports.
+	// everyone else depends entirely on life support.
+	cs.lifeSupportCapacity = naturalCapacity(cs)
+	if cs.lifeSupportCapacity != 0 {
+		cs.Log("  %13d natural capacity (habitability %d)\n", cs.lifeSupportCapacity, cs.Planet.HabitabilityNo)
 	}
 
 	// find fuel in inventory.
@@ -135,16 +159,24 @@ func (cs *CorS) lifeSupportInitialization(pos []*PhaseOrders) {
 	for _, u := range cs.Hull {
 		if u.Unit.Kind != "life-support" {
 			continue
-		} else if fuel.stowed.available() == 0 {
+		} else if totalPop(cs) <= cs.lifeSupportCapacity {
+			// don't burn fuel on units that aren't needed
+			break
+		} else if fuel == nil || fuel.stowed.available() == 0 {
 			cs.Log("  **** no fuel available for life support!\n")
 			break
 		}
-		// allocate fuel to the life support unit
-		fuelNeeded := int(math.Ceil(u.Unit.FuelPerUnitPerTurn * float64(u.operational.available())))
+		// allocate fuel to only as many life support units as needed
+		lsuNeeded := u.operational.available()
+		perUnit := u.Unit.TechLevel * u.Unit.TechLevel
+		if n := (totalPop(cs) - cs.lifeSupportCapacity + perUnit - 1) / perUnit; n < lsuNeeded {
+			lsuNeeded = n
+		}
+		fuelNeeded := int(math.Ceil(u.Unit.FuelPerUnitPerTurn * float64(lsuNeeded)))
 		if fuelNeeded == 0 {
 			continue
 		}
-		fuelAllocated := fuel.stowed.allocate(fuelNeeded)
+		fuelAllocated := fuel.stowed.remove(fuelNeeded)
 		lsuActivated := int(float64(fuelAllocated) / u.Unit.FuelPerUnitPerTurn)
 		u.operational.allocate(lsuActivated)
 
@@ -216,12 +248,13 @@ func (d Deposits) Swap(i, j int) {
 // FactoryGroup is a group of factories on a ship or colony.
 // Each group is dedicated to manufacturing one type of unit.
 type FactoryGroup struct {
-	CorS     *CorS          // ship or colony that controls the group
-	Id       int            // unique identifier
-	No       int            // group number, range 1...255
-	Product  *Unit          // unit being produced by the group
-	Units    InventoryUnits // units assigned to the group
-	StageQty [4]int         // assumes four turns to produce a single unit
+	CorS      *CorS          // ship or colony that controls the group
+	Id        int            // unique identifier
+	No        int            // group number, range 1...255
+	Product   *Unit          // unit being produced by the group
+	Units     InventoryUnits // units assigned to the group
+	StageQty  [4]int         // assumes four turns to produce a single unit
+	IdleTurns int            // turns the group is idle while retooling
 }
 
 type FactoryGroups []*FactoryGroup
@@ -306,6 +339,18 @@ func (r *requisition) available() (qty int) {
 	return qty
 }
 
+// remove takes available units out of inventory, usually to move them somewhere else.
+// removed units are booked as destroyed so that the bookkeeping phase drops them.
+// it returns the number of units removed, which will be less than the requested
+// amount when there aren't enough units available.
+func (r *requisition) remove(qty int) int {
+	if r.available() < qty {
+		qty = r.available()
+	}
+	r.destroyed += qty
+	return qty
+}
+
 // create adds new units.
 // they will not be available until after the bookkeeping phase.
 func (r *requisition) create(qty int) {
@@ -397,15 +442,41 @@ func (u InventoryUnits) Swap(i, j int) {
 	u[i], u[j] = u[j], u[i]
 }
 
+// MarketReport is the activity for a single unit during the last trade phase.
+type MarketReport struct {
+	Unit      *Unit
+	BidQty    int     // units that buyers asked for
+	AskQty    int     // units that sellers offered
+	Volume    int     // units that changed hands
+	LowPrice  int     // in gold per unit
+	HighPrice int     // in gold per unit
+	AvgPrice  float64 // in gold per unit
+}
+
+type MarketReports []*MarketReport
+
+func (m MarketReports) Len() int {
+	return len(m)
+}
+
+func (m MarketReports) Less(i, j int) bool {
+	return m[i].Unit.Id < m[j].Unit.Id
+}
+
+func (m MarketReports) Swap(i, j int) {
+	m[i], m[j] = m[j], m[i]
+}
+
 // MineGroup is a group of mines working a single deposit.
 // All mine units in a group must be the same type and tech level.
 type MineGroup struct {
-	CorS     *CorS // colony that controls the group
-	Id       int   // unique identifier
-	No       int
-	Deposit  *Deposit       // deposit being mined
-	Unit     *InventoryUnit // mine units in the group
-	StageQty [4]int         // assumes four turns to produce a single unit
+	CorS      *CorS // colony that controls the group
+	Id        int   // unique identifier
+	No        int
+	Deposit   *Deposit       // deposit being mined
+	Unit      *InventoryUnit // mine units in the group
+	StageQty  [4]int         // assumes four turns to produce a single unit
+	IdleTurns int            // turns the group is idle while retooling
 }
 
 type MineGroups []*MineGroup
@@ -427,17 +498,17 @@ func (m MineGroups) Swap(i, j int) {
 // players to control ships and colonies in the nation.
 // These players are called viceroys or regents.
 type Nation struct {
-	Id                 int     // unique id for nation
-	No                 int     // nation number, starts at 1
-	Name               string  // unique name for this nation
-	GovtName           string  // name of the government
-	GovtKind           string  // kind of government
-	HomePlanet         *Planet // nation's home planet
-	ControlledBy       *Player // player controlling this nation
-	Speciality         string  // nation's speciality for research
-	TechLevel          int     // current tech level of the nation
-	ResearchPointsPool int     // points in pool
-	// not used currently
+	Id                 int             // unique id for nation
+	No                 int             // nation number, starts at 1
+	Name               string          // unique name for this nation
+	GovtName           string          // name of the government
+	GovtKind           string          // kind of government
+	HomePlanet         *Planet         // nation's home planet
+	ControlledBy       *Player         // player controlling this nation
+	Speciality         string          // nation's speciality for research
+	TechLevel          int             // current tech level of the nation
+	ResearchPointsPool int             // points in pool
+	Surveys            map[int]*Survey // planets the nation has surveyed, key is planet id
 	Skills
 }
 
@@ -468,14 +539,18 @@ func (pay Pay) totalPay(pop Population, code string) int {
 }
 
 type Player struct {
-	Id        int     // unique id for a player
-	UserId    int     // user that controls this player
-	Name      string  // unique name for this player
-	MemberOf  *Nation // nation the player is aligned with
-	ReportsTo *Player // player that this player reports to
-	Colonies  CorSs   // colonies controlled by this player
-	Ships     CorSs   // ships controlled by this player
-	Logger    struct {
+	Id              int      // unique id for a player
+	UserId          int      // user that controls this player
+	Name            string   // unique name for this player
+	MemberOf        *Nation  // nation the player is aligned with
+	ReportsTo       *Player  // player that this player reports to
+	Colonies        CorSs    // colonies controlled by this player
+	Ships           CorSs    // ships controlled by this player
+	CombatReport    []string // results of the last combat phase
+	EspionageReport []string // results of the last espionage phase
+	UnrestReport    []string // results of the last unrest phase
+	StandingOrders  []string // orders that are repeated every turn
+	Logger          struct {
 		MP *message.Printer
 		W  io.Writer
 	}
@@ -498,6 +573,11 @@ type Planet struct {
 	Colonies       CorSs
 	Deposits       Deposits
 	Ships          CorSs
+	prospected     bool // true once a survey has looked for new deposits this turn
+}
+
+func (p *Planet) String() string {
+	return fmt.Sprintf("%s%s#%d", p.System.Coords.String(), p.Star.Sequence, p.OrbitNo)
 }
 
 type Population struct {
@@ -510,6 +590,18 @@ type Population struct {
 	RebelPct               float64
 	BirthsPriorTurn        int
 	NaturalDeathsPriorTurn int
+	ImmigrantsPriorTurn    int
+	EmigrantsPriorTurn     int
+	StandardOfLiving       float64     // score from pay, rations, consumer goods, and habitability
+	Training               []*Trainees // drafted population that is still training
+}
+
+// Trainees are drafted population that will join their new class
+// once their training is complete.
+type Trainees struct {
+	Class     string // professional, soldier, unskilled, or construction-crew
+	Qty       int
+	TurnsLeft int
 }
 
 type population struct {
@@ -593,6 +685,39 @@ func (s Stars) Swap(i, j int) {
 	s[i], s[j] = s[j], s[i]
 }
 
+// Survey is what a nation learned about a planet the last time it was surveyed.
+// The planet may have changed since then.
+type Survey struct {
+	Planet         *Planet
+	Year           int // year the survey was made
+	Quarter        int // quarter the survey was made
+	Kind           string
+	HabitabilityNo int
+	Deposits       []*SurveyDeposit
+}
+
+type Surveys []*Survey
+
+func (s Surveys) Len() int {
+	return len(s)
+}
+
+func (s Surveys) Less(i, j int) bool {
+	return s[i].Planet.Id < s[j].Planet.Id
+}
+
+func (s Surveys) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+// SurveyDeposit is what a nation learned about a deposit during a survey.
+type SurveyDeposit struct {
+	No           int   // number of deposit on planet
+	Product      *Unit // fuel, gold, metallic, non-metallic
+	YieldPct     float64
+	RemainingQty int // in metric tonnes
+}
+
 type System struct {
 	Id     int // unique identifier
 	Coords Coordinates
@@ -601,21 +726,29 @@ type System struct {
 
 // Unit is a thing in the game.
 type Unit struct {
-	Id                    int // unique identifier
-	Kind                  string
-	Code                  string
-	TechLevel             int
-	Name                  string
-	Description           string
-	MassPerUnit           float64 // mass (in metric tonnes) of a single unit
-	VolumePerUnit         float64 // volume (in cubic meters) of a single unit
-	Hudnut                bool    // if true, unit can be disassembled when stowed
-	StowedVolumePerUnit   float64 // volume (in cubic meters) of a single unit when stowed
-	FuelPerUnitPerTurn    float64
-	MetsPerUnitPerTurn    float64
-	NonMetsPerUnitPerTurn float64
+	Id                        int // unique identifier
+	Kind                      string
+	Code                      string
+	TechLevel                 int
+	Name                      string
+	Description               string
+	MassPerUnit               float64 // mass (in metric tonnes) of a single unit
+	VolumePerUnit             float64 // volume (in cubic meters) of a single unit
+	Hudnut                    bool    // if true, unit can be disassembled when stowed
+	StowedVolumePerUnit       float64 // volume (in cubic meters) of a single unit when stowed
+	FuelPerUnitPerTurn        float64
+	FuelPerUnitPerCombatRound float64
+	MetsPerUnitPerTurn        float64
+	NonMetsPerUnitPerTurn     float64
+	UpkeepMetsPerUnitPerTurn  float64 // metallics needed to maintain a single unit
+	UpkeepNmtsPerUnitPerTurn  float64 // non-metallics needed to maintain a single unit
+	UpkeepCngdPerUnitPerTurn  float64 // consumer goods needed to maintain a single unit
 }
 
 func (u *Unit) fuelUsed(qty int) int {
 	return int(math.Ceil(u.FuelPerUnitPerTurn * float64(qty)))
 }
+
+func (u *Unit) fuelUsedInCombat(qty int) int {
+	return int(math.Ceil(u.FuelPerUnitPerCombatRound * float64(qty)))
+}
@@ -0,0 +1,138 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+import (
+	"testing"
+)
+
+func TestRequisitionRemove(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		r             requisition
+		qty           int
+		want          int // units removed
+		wantAvailable int
+	}{
+		{name: "some", r: requisition{initial: 10}, qty: 4, want: 4, wantAvailable: 6},
+		{name: "all", r: requisition{initial: 10}, qty: 10, want: 10, wantAvailable: 0},
+		{name: "more than available", r: requisition{initial: 10, allocated: 8}, qty: 4, want: 2, wantAvailable: 0},
+		{name: "already destroyed", r: requisition{initial: 10, destroyed: 10}, qty: 1, want: 0, wantAvailable: 0},
+		{name: "created units wait for bookkeeping", r: requisition{created: 5}, qty: 3, want: 0, wantAvailable: 0},
+	} {
+		destroyed := tc.r.destroyed
+		if got := tc.r.remove(tc.qty); tc.want != got {
+			t.Errorf("%s: removed: want %d: got %d", tc.name, tc.want, got)
+		}
+		// removed units are booked as destroyed so that bookkeeping drops them
+		if got := tc.r.destroyed - destroyed; tc.want != got {
+			t.Errorf("%s: destroyed: want %d: got %d", tc.name, tc.want, got)
+		}
+		if got := tc.r.available(); tc.wantAvailable != got {
+			t.Errorf("%s: available: want %d: got %d", tc.name, tc.wantAvailable, got)
+		}
+	}
+}
+
+func TestRequisitionDestroy(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		r             requisition
+		qty           int
+		want          int // units destroyed
+		wantDestroyed int
+		wantAllocated int
+	}{
+		{name: "no inventory", r: requisition{}, qty: 4, want: 0},
+		{name: "unallocated", r: requisition{initial: 10}, qty: 4, want: 4, wantDestroyed: 4},
+		{name: "partly allocated", r: requisition{initial: 10, allocated: 5}, qty: 4, want: 4, wantDestroyed: 4, wantAllocated: 3},
+		{name: "fully allocated", r: requisition{initial: 10, allocated: 10}, qty: 3, want: 3, wantDestroyed: 3, wantAllocated: 7},
+		{name: "all that remain", r: requisition{initial: 10, destroyed: 2, allocated: 4}, qty: 9, want: 8, wantDestroyed: 10},
+	} {
+		if got := tc.r.destroy(tc.qty); tc.want != got {
+			t.Errorf("%s: destroyed: want %d: got %d", tc.name, tc.want, got)
+		}
+		if tc.wantDestroyed != tc.r.destroyed {
+			t.Errorf("%s: destroyed quantity: want %d: got %d", tc.name, tc.wantDestroyed, tc.r.destroyed)
+		}
+		if tc.wantAllocated != tc.r.allocated {
+			t.Errorf("%s: allocated quantity: want %d: got %d", tc.name, tc.wantAllocated, tc.r.allocated)
+		}
+	}
+}
+
+func TestUpdateInventory(t *testing.T) {
+	fuel := &Unit{Id: 1, Kind: "fuel", Code: "FUEL"}
+	stun := &Unit{Id: 2, Kind: "structural", Code: "STUN"}
+	for _, tc := range []struct {
+		name       string
+		active     int // fuel at the start of the turn
+		stowed     int
+		turn       func(cs *CorS) int // runs the turn and returns the quantity it moved
+		want       int
+		wantActive int // fuel after bookkeeping
+		wantStowed int
+	}{
+		{name: "fuel burned from stowed units first",
+			active: 5, stowed: 10,
+			turn:       func(cs *CorS) int { return removeFuel(cs, 12) },
+			want:       12,
+			wantActive: 3, wantStowed: 0,
+		},
+		{name: "burning more fuel than on hand",
+			active: 5, stowed: 10,
+			turn:       func(cs *CorS) int { return removeFuel(cs, 20) },
+			want:       15,
+			wantActive: 0, wantStowed: 0,
+		},
+		{name: "created fuel can't be burned the same turn",
+			active: 0, stowed: 10,
+			turn: func(cs *CorS) int {
+				cs.Inventory[0].stowed.create(6)
+				return removeFuel(cs, 12)
+			},
+			want:       10,
+			wantActive: 0, wantStowed: 6,
+		},
+		{name: "removed and created",
+			active: 0, stowed: 10,
+			turn: func(cs *CorS) int {
+				removed := cs.Inventory[0].stowed.remove(4)
+				cs.Inventory[0].stowed.create(6)
+				return removed
+			},
+			want:       4,
+			wantActive: 0, wantStowed: 12,
+		},
+	} {
+		cs := &CorS{HullId: "C1", Inventory: InventoryUnits{{Unit: fuel, ActiveQty: tc.active, StowedQty: tc.stowed}, {Unit: stun, StowedQty: 10}}}
+		cs.InitializeInventory()
+		if got := tc.turn(cs); tc.want != got {
+			t.Errorf("%s: moved: want %d: got %d", tc.name, tc.want, got)
+		}
+		cs.updateInventory()
+		if u := cs.Inventory[0]; tc.wantActive != u.ActiveQty || tc.wantStowed != u.StowedQty {
+			t.Errorf("%s: active and stowed: want %d and %d: got %d and %d", tc.name, tc.wantActive, tc.wantStowed, u.ActiveQty, u.StowedQty)
+		}
+		// burning fuel doesn't touch other units
+		if u := cs.Inventory[1]; u.ActiveQty != 0 || u.StowedQty != 10 {
+			t.Errorf("%s: %s: want 0 and 10: got %d and %d", tc.name, u.Unit.Code, u.ActiveQty, u.StowedQty)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+import (
+	"fmt"
+	"math"
+)
+
+// decayPct is the share of unmaintained units that break down each turn.
+// Units that can be disassembled are shut down and stowed; everything else is destroyed.
+const decayPct = 0.1
+
+// ExecuteMaintenancePhase records the maintenance priorities and then maintains
+// the operational units in every colony and ship.
+// Units named in maintain orders are maintained first, then the hull, then inventory.
+func (e *Engine) ExecuteMaintenancePhase(pos []*PhaseOrders) (errs []error) {
+	for _, o := range pos {
+		o.Player.Log("\n\nMaintenance -----------------------------------------------------\n")
+		for _, order := range o.Maintain {
+			if o.skip(e, order) {
+				continue
+			}
+			if err := order.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	for _, cs := range e.sortedCorS() {
+		maintainUnits(cs)
+	}
+	return errs
+}
+
+// Execute moves a unit to the front of the maintenance queue for the colony or ship.
+// Will fail if the colony or ship is not controlled by the player.
+func (o *MaintainOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	p.Log("  maintain %s: %s\n", o.CorS, o.Unit)
+	cs, err := findControlled(e, p, "maintain", o.CorS)
+	if err != nil {
+		return err
+	}
+	u, ok := unitFromString(e, o.Unit)
+	if !ok {
+		p.Log("           %s: no such unit %q\n", o.CorS, o.Unit)
+		return fmt.Errorf("no such unit %q", o.Unit)
+	}
+	cs.maintainFirst = append(cs.maintainFirst, u)
+	return nil
+}
+
+// maintainUnits spends metallics, non-metallics, and consumer goods to keep operational units running.
+// Units that can't be maintained decay.
+func maintainUnits(cs *CorS) {
+	var queue InventoryUnits
+	queued := make(map[*InventoryUnit]bool)
+	for _, unit := range cs.maintainFirst {
+		for _, units := range []InventoryUnits{cs.Hull, cs.Inventory} {
+			for _, u := range units {
+				if u.Unit.Id == unit.Id && !queued[u] {
+					queue, queued[u] = append(queue, u), true
+				}
+			}
+		}
+	}
+	for _, units := range []InventoryUnits{cs.Hull, cs.Inventory} {
+		for _, u := range units {
+			if !queued[u] {
+				queue, queued[u] = append(queue, u), true
+			}
+		}
+	}
+
+	mets, nmts, cngd := availableKind(cs, "metallics"), availableKind(cs, "non-metallics"), availableKind(cs, "consumer-goods")
+	for _, u := range queue {
+		qty := u.operational.available()
+		if qty == 0 || !needsUpkeep(u.Unit) {
+			continue
+		}
+
+		maintained := qty
+		if limit := upkeepLimit(mets, u.Unit.UpkeepMetsPerUnitPerTurn); limit < maintained {
+			maintained = limit
+		}
+		if limit := upkeepLimit(nmts, u.Unit.UpkeepNmtsPerUnitPerTurn); limit < maintained {
+			maintained = limit
+		}
+		if limit := upkeepLimit(cngd, u.Unit.UpkeepCngdPerUnitPerTurn); limit < maintained {
+			maintained = limit
+		}
+		mets -= consume(cs, "metallics", int(math.Ceil(float64(maintained)*u.Unit.UpkeepMetsPerUnitPerTurn)))
+		nmts -= consume(cs, "non-metallics", int(math.Ceil(float64(maintained)*u.Unit.UpkeepNmtsPerUnitPerTurn)))
+		cngd -= consume(cs, "consumer-goods", int(math.Ceil(float64(maintained)*u.Unit.UpkeepCngdPerUnitPerTurn)))
+		cs.Log("  maintain %s: %-7s  %13d operational  %13d maintained\n", cs.HullId, u.Unit.Code, qty, maintained)
+
+		if unmaintained := qty - maintained; unmaintained > 0 {
+			decayed := u.operational.remove(int(math.Ceil(float64(unmaintained) * decayPct)))
+			if u.Unit.Hudnut {
+				u.stowed.create(decayed)
+				cs.Log("           %s: %-7s  %13d shut down and stowed\n", cs.HullId, u.Unit.Code, decayed)
+			} else {
+				cs.Log("           %s: %-7s  %13d worn out\n", cs.HullId, u.Unit.Code, decayed)
+			}
+		}
+	}
+}
+
+// availableKind returns the number of units of the given kind in inventory that haven't been used this turn.
+func availableKind(cs *CorS, kind string) (qty int) {
+	for _, u := range cs.Inventory {
+		if u.Unit.Kind == kind {
+			qty += u.stowed.available() + u.operational.available()
+		}
+	}
+	return qty
+}
+
+// needsUpkeep returns true if the unit needs materials to keep running.
+func needsUpkeep(u *Unit) bool {
+	return !(isZero(u.UpkeepMetsPerUnitPerTurn) && isZero(u.UpkeepNmtsPerUnitPerTurn) && isZero(u.UpkeepCngdPerUnitPerTurn))
+}
+
+// upkeepLimit returns the number of units that the available material can maintain.
+func upkeepLimit(available int, perUnit float64) int {
+	if isZero(perUnit) {
+		return math.MaxInt
+	}
+	return int(float64(available) / perUnit)
+}
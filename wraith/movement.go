@@ -0,0 +1,213 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// drive thrust is the mass (in metric tonnes) that a single drive unit
+// can move one orbit (space-drive) or one light year (hyper-drive)
+// per tech level in a single turn.
+const (
+	hyperDriveThrust = 1_000
+	spaceDriveThrust = 1_000
+)
+
+// ExecuteMovementPhase runs all the orders in the movement phase.
+func (e *Engine) ExecuteMovementPhase(pos []*PhaseOrders) (errs []error) {
+	for _, o := range pos {
+		o.Player.Log("\n\nMovement --------------------------------------------------------\n")
+		for _, order := range o.Movement {
+			if o.skip(e, order) {
+				continue
+			}
+			if err := order.Jump.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+			if err := order.Move.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// Execute jumps a ship to an orbit in another system using the ship's hyper-drives.
+// Will fail if the ship is not controlled by the player, the destination is out of range,
+// or there is not enough fuel to power the drives.
+func (o *JumpShipOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	p.Log("  jump %s: %s%s#%d\n", o.Id, o.Coords.String(), o.Star, o.OrbitNo)
+
+	// find ship
+	s, ok := e.findShip(o.Id)
+	if !ok {
+		p.Log("       %s: no such ship\n", o.Id)
+		return fmt.Errorf("no such ship %q", o.Id)
+	}
+	// fail if controlled by another player
+	if s.ControlledBy != nil && s.ControlledBy != p {
+		p.Log("       %s: no such ship\n", o.Id)
+		return fmt.Errorf("no such ship %q", o.Id)
+	}
+
+	// find the destination
+	dest, ok := e.findPlanet(o.Coords, o.Star, o.OrbitNo)
+	if !ok {
+		p.Log("       %s: jump failed: no such location\n", o.Id)
+		return fmt.Errorf("no such location %s%s#%d", o.Coords.String(), o.Star, o.OrbitNo)
+	} else if dest.System == s.Planet.System {
+		p.Log("       %s: jump failed: destination is in the current system\n", o.Id)
+		return fmt.Errorf("%s: jump within system", o.Id)
+	}
+	dx, dy, dz := dest.System.Coords.X-s.Planet.System.Coords.X, dest.System.Coords.Y-s.Planet.System.Coords.Y, dest.System.Coords.Z-s.Planet.System.Coords.Z
+	distance := math.Sqrt(float64(dx*dx + dy*dy + dz*dz))
+
+	driveRange, fuelNeeded := driveCapacity(s, "hyper-drive", hyperDriveThrust)
+	p.Log("       %s: %-20s  %12.2f requested  %13.2f available\n", o.Id, "light years", distance, driveRange)
+	p.Log("       %s: %-20s  %12d requested  %13d available\n", o.Id, "fuel", fuelNeeded, availableFuel(s))
+	if driveRange == 0 {
+		p.Log("       %s: jump failed: no operational hyper-drives\n", o.Id)
+		return fmt.Errorf("%s: no hyper-drives", o.Id)
+	} else if distance > driveRange {
+		p.Log("       %s: jump failed: destination out of range\n", o.Id)
+		return fmt.Errorf("%s: out of range", o.Id)
+	} else if availableFuel(s) < fuelNeeded {
+		p.Log("       %s: jump failed: not enough fuel\n", o.Id)
+		return fmt.Errorf("%s: not enough fuel", o.Id)
+	}
+
+	burnFuel(s, fuelNeeded)
+	relocate(s, dest)
+	p.Log("       %s: arrived at %s\n", o.Id, dest.String())
+
+	return nil
+}
+
+// Execute moves a ship to another orbit in its current system using the ship's space-drives.
+// Will fail if the ship is not controlled by the player, the destination is out of range,
+// or there is not enough fuel to power the drives.
+func (o *MoveShipOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	// find ship. the destination defaults to the ship's current system and star,
+	// but we don't reveal where the ship is unless the player controls it.
+	s, ok := e.findShip(o.Id)
+	var coords Coordinates
+	var star string
+	if ok && s.Planet != nil && (s.ControlledBy == nil || s.ControlledBy == p) {
+		coords, star = s.Planet.System.Coords, s.Planet.Star.Sequence
+	}
+	if o.Coords != nil {
+		coords = *o.Coords
+	}
+	if o.Star != "" {
+		star = o.Star
+	}
+	p.Log("  move %s: %s%s#%d\n", o.Id, coords.String(), star, o.OrbitNo)
+	if !ok {
+		p.Log("       %s: no such ship\n", o.Id)
+		return fmt.Errorf("no such ship %q", o.Id)
+	}
+	// fail if controlled by another player
+	if s.ControlledBy != nil && s.ControlledBy != p {
+		p.Log("       %s: no such ship\n", o.Id)
+		return fmt.Errorf("no such ship %q", o.Id)
+	}
+
+	// find the destination. the space-drive can't leave the current system.
+	if coords != s.Planet.System.Coords {
+		p.Log("       %s: move failed: destination is not in the current system\n", o.Id)
+		return fmt.Errorf("%s: move out of system", o.Id)
+	}
+	dest, ok := e.findPlanet(coords, star, o.OrbitNo)
+	if !ok {
+		p.Log("       %s: move failed: no such location\n", o.Id)
+		return fmt.Errorf("no such location %s%s#%d", coords.String(), star, o.OrbitNo)
+	} else if dest == s.Planet {
+		p.Log("       %s: already at %s\n", o.Id, dest.String())
+		return nil
+	}
+	// moving between stars means leaving orbit of one and entering orbit of the other
+	var distance float64
+	if dest.Star == s.Planet.Star {
+		distance = math.Abs(float64(dest.OrbitNo - s.Planet.OrbitNo))
+	} else {
+		distance = float64(dest.OrbitNo + s.Planet.OrbitNo)
+	}
+
+	driveRange, fuelNeeded := driveCapacity(s, "space-drive", spaceDriveThrust)
+	p.Log("       %s: %-20s  %12.2f requested  %13.2f available\n", o.Id, "orbits", distance, driveRange)
+	p.Log("       %s: %-20s  %12d requested  %13d available\n", o.Id, "fuel", fuelNeeded, availableFuel(s))
+	if driveRange == 0 {
+		p.Log("       %s: move failed: no operational space-drives\n", o.Id)
+		return fmt.Errorf("%s: no space-drives", o.Id)
+	} else if distance > driveRange {
+		p.Log("       %s: move failed: destination out of range\n", o.Id)
+		return fmt.Errorf("%s: out of range", o.Id)
+	} else if availableFuel(s) < fuelNeeded {
+		p.Log("       %s: move failed: not enough fuel\n", o.Id)
+		return fmt.Errorf("%s: not enough fuel", o.Id)
+	}
+
+	burnFuel(s, fuelNeeded)
+	relocate(s, dest)
+	p.Log("       %s: arrived at %s\n", o.Id, dest.String())
+
+	return nil
+}
+
+// driveCapacity returns the range of the operational drives of the given kind
+// and the fuel needed to run them for a turn.
+// range is the total thrust of the drives divided by the total mass of the ship.
+func driveCapacity(cs *CorS, kind string, thrust float64) (driveRange float64, fuelNeeded int) {
+	mass := totalMass(cs)
+	if mass == 0 {
+		return 0, 0
+	}
+	for _, u := range cs.Hull {
+		if u.Unit.Kind != kind || u.ActiveQty == 0 {
+			continue
+		}
+		driveRange += thrust * float64(u.ActiveQty*u.Unit.TechLevel) / float64(mass)
+		fuelNeeded += u.Unit.fuelUsed(u.ActiveQty)
+	}
+	return driveRange, fuelNeeded
+}
+
+// relocate moves a colony or ship from its current planet to the destination.
+func relocate(cs *CorS, dest *Planet) {
+	if cs.Planet != nil {
+		for i, s := range cs.Planet.Ships {
+			if s == cs {
+				cs.Planet.Ships = append(cs.Planet.Ships[:i], cs.Planet.Ships[i+1:]...)
+				break
+			}
+		}
+	}
+	cs.Planet = dest
+	dest.Ships = append(dest.Ships, cs)
+	sort.Sort(dest.Ships)
+}
@@ -20,7 +20,6 @@ package wraith
 
 import (
 	"fmt"
-	"github.com/mdhender/wraith/internal/orders"
 	"log"
 	"math"
 	"sort"
@@ -30,20 +29,155 @@ import (
 type PhaseOrders struct {
 	Player *Player
 	// orders sorted by phase
-	Combat      []*orders.Order
-	SetUp       []*orders.Order
-	Disassembly []*orders.Order
+	Combat      []*CombatPhaseOrder
+	SetUp       []*SetUpOrder
+	Disassembly []*DisassembleOrder
 	Retool      []*RetoolPhaseOrder
-	Transfer    []*orders.Order
+	Transfer    []*TransferPhaseOrder
 	Assembly    []*AssemblyPhaseOrder
-	Trade       []*orders.Order
-	Survey      []*orders.Order
-	Espionage   []*orders.Order
-	Movement    []*orders.Order
-	Draft       []*orders.Order
-	Pay         []*orders.Order
-	Ration      []*orders.Order
+	Build       []*BuildPhaseOrder
+	Trade       []*TradePhaseOrder
+	Survey      []*SurveyOrder
+	Espionage   []*EspionagePhaseOrder
+	Movement    []*MovementPhaseOrder
+	Draft       []*DraftOrder
+	Pay         []*PayOrder
+	Ration      []*RationOrder
+	Research    []*ResearchOrder
 	Control     []*ControlPhaseOrder
+	Maintain    []*MaintainOrder
+	// conditions for orders that only run when the condition holds, keyed by the order
+	Conditions map[interface{}]*Condition
+}
+
+// Add adds all the orders in src, along with their conditions, to the player's orders.
+// It returns the orders that were added.
+func (po *PhaseOrders) Add(src *PhaseOrders) (added []interface{}) {
+	for _, o := range src.Combat {
+		po.Combat, added = append(po.Combat, o), append(added, o)
+	}
+	for _, o := range src.SetUp {
+		po.SetUp, added = append(po.SetUp, o), append(added, o)
+	}
+	for _, o := range src.Disassembly {
+		po.Disassembly, added = append(po.Disassembly, o), append(added, o)
+	}
+	for _, o := range src.Retool {
+		po.Retool, added = append(po.Retool, o), append(added, o)
+	}
+	for _, o := range src.Transfer {
+		po.Transfer, added = append(po.Transfer, o), append(added, o)
+	}
+	for _, o := range src.Assembly {
+		po.Assembly, added = append(po.Assembly, o), append(added, o)
+	}
+	for _, o := range src.Build {
+		po.Build, added = append(po.Build, o), append(added, o)
+	}
+	for _, o := range src.Trade {
+		po.Trade, added = append(po.Trade, o), append(added, o)
+	}
+	for _, o := range src.Survey {
+		po.Survey, added = append(po.Survey, o), append(added, o)
+	}
+	for _, o := range src.Espionage {
+		po.Espionage, added = append(po.Espionage, o), append(added, o)
+	}
+	for _, o := range src.Movement {
+		po.Movement, added = append(po.Movement, o), append(added, o)
+	}
+	for _, o := range src.Draft {
+		po.Draft, added = append(po.Draft, o), append(added, o)
+	}
+	for _, o := range src.Pay {
+		po.Pay, added = append(po.Pay, o), append(added, o)
+	}
+	for _, o := range src.Ration {
+		po.Ration, added = append(po.Ration, o), append(added, o)
+	}
+	for _, o := range src.Research {
+		po.Research, added = append(po.Research, o), append(added, o)
+	}
+	for _, o := range src.Control {
+		po.Control, added = append(po.Control, o), append(added, o)
+	}
+	for _, o := range src.Maintain {
+		po.Maintain, added = append(po.Maintain, o), append(added, o)
+	}
+	for o, c := range src.Conditions {
+		if po.Conditions == nil {
+			po.Conditions = make(map[interface{}]*Condition)
+		}
+		po.Conditions[o] = c
+	}
+	return added
+}
+
+type MaintainOrder struct {
+	CorS string // id of ship or colony
+	Unit string // unit to maintain before all others
+}
+
+type BuildPhaseOrder struct {
+	Colony *BuildColonyOrder
+	Ship   *BuildShipOrder
+}
+type BuildColonyOrder struct {
+	CorS     string // id of ship or colony building the new colony
+	Coords   Coordinates
+	Star     string
+	OrbitNo  int
+	Kind     string // enclosed or surface
+	Quantity int    // number of structural units for the hull
+	Unit     string
+}
+type BuildShipOrder struct {
+	CorS     string // id of ship or colony building the new ship
+	Quantity int    // number of structural units for the hull
+	Unit     string
+}
+
+type TradePhaseOrder struct {
+	Buy   *BuyOrder
+	Offer *OfferOrder
+	Sell  *SellOrder
+}
+type BuyOrder struct {
+	CorS     string // id of ship or colony buying
+	Quantity int
+	Unit     string
+	Price    int // most gold per unit the buyer will pay
+}
+type OfferOrder struct {
+	CorS         string // id of ship or colony making the offer
+	Quantity     int
+	Unit         string
+	Partner      string // id of ship or colony the offer is made to
+	WantQuantity int    // quantity wanted in exchange
+	WantUnit     string // unit wanted in exchange
+}
+type SellOrder struct {
+	CorS     string // id of ship or colony selling
+	Quantity int
+	Unit     string
+	Price    int // least gold per unit the seller will take
+}
+
+type TransferPhaseOrder struct {
+	Population *TransferPopulationOrder
+	Unit       *TransferUnitOrder
+}
+type TransferPopulationOrder struct {
+	From     string // id of ship or colony to transfer from
+	To       string // id of ship or colony to transfer to
+	Quantity int
+	Class    string // professional, soldier, unskilled, unemployed, construction-crew, or spy-team
+}
+type TransferUnitOrder struct {
+	From     string // id of ship or colony to transfer from
+	To       string // id of ship or colony to transfer to
+	Quantity int
+	Unit     string
 }
 
 type AssemblyPhaseOrder struct {
@@ -85,21 +219,121 @@ type AssembleSpyTeamOrder struct {
 	pro, uns requisition
 }
 
+type CombatPhaseOrder struct {
+	Attack *AttackOrder
+	Defend *DefendOrder
+	Raid   *RaidOrder
+}
+type AttackOrder struct {
+	CorS   string // id of ship or colony attacking
+	Target string // id of ship or colony being attacked
+}
+type DefendOrder struct {
+	CorS string // id of ship or colony to defend
+}
+type RaidOrder struct {
+	CorS   string // id of ship or colony raiding
+	Target string // id of ship or colony being raided
+	Cargo  string // unit to carry off
+}
+
+type EspionagePhaseOrder struct {
+	CounterIntel *CounterIntelOrder
+	GatherIntel  *GatherIntelOrder
+	Incite       *InciteOrder
+	Sabotage     *SabotageOrder
+}
+type CounterIntelOrder struct {
+	CorS     string // id of ship or colony to protect
+	Quantity int    // number of spy teams to assign
+}
+type GatherIntelOrder struct {
+	CorS     string // id of ship or colony sending the spy teams
+	Quantity int    // number of spy teams to send
+	Target   string // id of ship or colony being spied on
+}
+type InciteOrder struct {
+	CorS     string // id of ship or colony sending the spy teams
+	Quantity int    // number of spy teams to send
+	Target   string // id of ship or colony being incited to rebel
+}
+type SabotageOrder struct {
+	CorS     string // id of ship or colony sending the spy teams
+	Quantity int    // number of spy teams to send
+	Target   string // id of ship or colony being sabotaged
+	Group    string // factory group being sabotaged, FG#
+}
+
+type ResearchOrder struct {
+	Target string // tech-level or the name of a skill
+}
+
 type RetoolPhaseOrder struct {
 	FactoryGroup *RetoolFactoryGroupOrder
 	MiningGroup  *RetoolMiningGroupOrder
 }
 type RetoolFactoryGroupOrder struct {
-	CorS     string // id of ship or colony to assemble in
-	Quantity int
-	Unit     string
-	Product  string
+	CorS    string // id of ship or colony the group is in
+	Group   string // id of factory group to retool
+	Product string // unit the group will produce
 }
 type RetoolMiningGroupOrder struct {
-	CorS     string // id of ship or colony to assemble in
-	Quantity int
-	Unit     string
-	Product  string
+	CorS    string // id of colony the group is in
+	Group   string // id of mine group to retool
+	Deposit string // id of deposit the group will mine
+}
+
+type MovementPhaseOrder struct {
+	Jump *JumpShipOrder
+	Move *MoveShipOrder
+}
+type JumpShipOrder struct {
+	Id      string      // id of ship to jump
+	Coords  Coordinates // coordinates of the destination system
+	Star    string      // sequence of the destination star
+	OrbitNo int         // destination orbit
+}
+type MoveShipOrder struct {
+	Id      string       // id of ship to move
+	Coords  *Coordinates // nil for the ship's current system
+	Star    string       // empty for the ship's current star
+	OrbitNo int          // destination orbit
+}
+
+type SetUpOrder struct {
+	CorS     string // id of ship or colony to set up units in
+	Quantity int    // number of stowed units to set up
+	Unit     string // unit to set up
+}
+
+type DisassembleOrder struct {
+	CorS     string // id of ship or colony to disassemble units in
+	Quantity int    // number of operational units to disassemble
+	Unit     string // unit to disassemble
+}
+
+type SurveyOrder struct {
+	CorS    string      // id of ship or colony doing the survey
+	Coords  Coordinates // system to survey
+	Star    string      // optional star in the system to survey
+	OrbitNo int         // optional orbit of the star to survey
+}
+
+type DraftOrder struct {
+	CorS     string // id of ship or colony to draft from
+	Quantity int    // number of units to draft
+	Class    string // professional, soldier, unskilled, or construction-crew
+}
+
+type PayOrder struct {
+	CorS  string  // id of ship or colony to set pay for
+	Class string  // professional, soldier, or unskilled
+	Pct   float64 // pay rate, 1.0 is the base rate
+}
+type RationOrder struct {
+	CorS  string  // id of ship or colony to set rations for
+	Class string  // professional, soldier, unskilled, or unemployed
+	Pct   float64 // ration rate, 1.0 is the base rate
 }
 
 type ControlPhaseOrder struct {
@@ -123,13 +357,59 @@ type NameShipOrder struct {
 	Name string // name to assign to ship
 }
 
+// phaseNames are the phases in the order that Execute runs them.
+var phaseNames = []string{
+	"power-allocation", "labor-allocation", "life-support",
+	"farm-production", "mine-production", "factory-production", "research",
+	"combat", "setup", "disassembly", "retool", "transfer", "assembly", "build",
+	"trade", "survey", "espionage", "movement", "draft", "pay", "ration", "control",
+	"unrest", "maintenance",
+}
+
+// phaseAliases maps the old names of phases to their current names.
+var phaseAliases = map[string]string{
+	"fuel-allocation": "power-allocation",
+}
+
+// PhaseName returns the current name of the phase.
+// Old names are accepted. Will fail if there is no such phase.
+func PhaseName(name string) (string, error) {
+	if alias, ok := phaseAliases[name]; ok {
+		name = alias
+	}
+	if indexOf(name, phaseNames) == -1 {
+		return "", fmt.Errorf("unknown phase %q", name)
+	}
+	return name, nil
+}
+
 // Execute runs all the orders in the list of phases.
 // If the list is empty, no phases will run.
+// The players take turns in a seeded rotation; see sequencePlayers.
 func (e *Engine) Execute(pos []*PhaseOrders, phases ...string) error {
-	if indexOf("fuel-allocation", phases) != -1 {
-		log.Printf("execute: fuel-allocation phase\n")
-		for _, err := range e.ExecuteFuelAllocationPhase(pos) {
-			log.Printf("execute: fuel-allocation: %v\n", err)
+	// accept the old names of phases
+	phases = append([]string{}, phases...)
+	for i, phase := range phases {
+		if alias, ok := phaseAliases[phase]; ok {
+			phases[i] = alias
+		}
+	}
+
+	for _, cs := range e.sortedCorS() {
+		cs.InitializeInventory()
+	}
+	// the reports only cover this turn
+	for _, p := range e.Players {
+		p.CombatReport, p.EspionageReport, p.UnrestReport = nil, nil, nil
+	}
+
+	// the players take turns in a rotation that is fair and repeatable
+	pos = e.sequencePlayers(pos)
+
+	if indexOf("power-allocation", phases) != -1 {
+		log.Printf("execute: power-allocation phase\n")
+		for _, err := range e.ExecutePowerAllocationPhase(pos) {
+			log.Printf("execute: power-allocation: %v\n", err)
 		}
 	}
 	if indexOf("labor-allocation", phases) != -1 {
@@ -162,6 +442,12 @@ func (e *Engine) Execute(pos []*PhaseOrders, phases ...string) error {
 			log.Printf("execute: factory-production: %v\n", err)
 		}
 	}
+	if indexOf("research", phases) != -1 {
+		log.Printf("execute: research phase\n")
+		for _, err := range e.ExecuteResearchPhase(pos) {
+			log.Printf("execute: research: %v\n", err)
+		}
+	}
 	if indexOf("combat", phases) != -1 {
 		log.Printf("execute: combat phase\n")
 		for _, err := range e.ExecuteCombatPhase(pos) {
@@ -169,17 +455,28 @@ func (e *Engine) Execute(pos []*PhaseOrders, phases ...string) error {
 		}
 	}
 	if indexOf("setup", phases) != -1 {
-		log.Printf("execute: setup phase: not implemented\n")
+		log.Printf("execute: setup phase\n")
+		for _, err := range e.ExecuteSetUpPhase(pos) {
+			log.Printf("execute: setup: %v\n", err)
+		}
 	}
 	if indexOf("disassembly", phases) != -1 {
-		log.Printf("execute: disassembly phase: not implemented\n")
+		log.Printf("execute: disassembly phase\n")
+		for _, err := range e.ExecuteDisassemblyPhase(pos) {
+			log.Printf("execute: disassembly: %v\n", err)
+		}
 	}
 	if indexOf("retool", phases) != -1 {
 		log.Printf("execute: retool phase\n")
-		e.ExecuteRetoolPhase(pos)
+		for _, err := range e.ExecuteRetoolPhase(pos) {
+			log.Printf("execute: retool: %v\n", err)
+		}
 	}
 	if indexOf("transfer", phases) != -1 {
-		log.Printf("execute: transfer phase: not implemented\n")
+		log.Printf("execute: transfer phase\n")
+		for _, err := range e.ExecuteTransferPhase(pos) {
+			log.Printf("execute: transfer: %v\n", err)
+		}
 	}
 	if indexOf("assembly", phases) != -1 {
 		log.Printf("execute: assembly phase\n")
@@ -187,26 +484,53 @@ func (e *Engine) Execute(pos []*PhaseOrders, phases ...string) error {
 			log.Printf("execute: assembly: %v\n", err)
 		}
 	}
+	if indexOf("build", phases) != -1 {
+		log.Printf("execute: build phase\n")
+		for _, err := range e.ExecuteBuildPhase(pos) {
+			log.Printf("execute: build: %v\n", err)
+		}
+	}
 	if indexOf("trade", phases) != -1 {
-		log.Printf("execute: trade phase: not implemented\n")
+		log.Printf("execute: trade phase\n")
+		for _, err := range e.ExecuteTradePhase(pos) {
+			log.Printf("execute: trade: %v\n", err)
+		}
 	}
 	if indexOf("survey", phases) != -1 {
-		log.Printf("execute: survey phase: not implemented\n")
+		log.Printf("execute: survey phase\n")
+		for _, err := range e.ExecuteSurveyPhase(pos) {
+			log.Printf("execute: survey: %v\n", err)
+		}
 	}
 	if indexOf("espionage", phases) != -1 {
-		log.Printf("execute: espionage phase: not implemented\n")
+		log.Printf("execute: espionage phase\n")
+		for _, err := range e.ExecuteEspionagePhase(pos) {
+			log.Printf("execute: espionage: %v\n", err)
+		}
 	}
 	if indexOf("movement", phases) != -1 {
-		log.Printf("execute: movement phase: not implemented\n")
+		log.Printf("execute: movement phase\n")
+		for _, err := range e.ExecuteMovementPhase(pos) {
+			log.Printf("execute: movement: %v\n", err)
+		}
 	}
 	if indexOf("draft", phases) != -1 {
-		log.Printf("execute: draft phase: not implemented\n")
+		log.Printf("execute: draft phase\n")
+		for _, err := range e.ExecuteDraftPhase(pos) {
+			log.Printf("execute: draft: %v\n", err)
+		}
 	}
 	if indexOf("pay", phases) != -1 {
-		log.Printf("execute: pay phase: not implemented\n")
+		log.Printf("execute: pay phase\n")
+		for _, err := range e.ExecutePayPhase(pos) {
+			log.Printf("execute: pay: %v\n", err)
+		}
 	}
 	if indexOf("ration", phases) != -1 {
-		log.Printf("execute: ration phase: not implemented\n")
+		log.Printf("execute: ration phase\n")
+		for _, err := range e.ExecuteRationPhase(pos) {
+			log.Printf("execute: ration: %v\n", err)
+		}
 	}
 	if indexOf("control", phases) != -1 {
 		log.Printf("execute: control phase\n")
@@ -215,40 +539,39 @@ func (e *Engine) Execute(pos []*PhaseOrders, phases ...string) error {
 		}
 	}
 
+	if indexOf("unrest", phases) != -1 {
+		log.Printf("execute: unrest phase\n")
+		for _, err := range e.ExecuteUnrestPhase(pos) {
+			log.Printf("execute: unrest: %v\n", err)
+		}
+	}
+
+	if indexOf("maintenance", phases) != -1 {
+		log.Printf("execute: maintenance phase\n")
+		for _, err := range e.ExecuteMaintenancePhase(pos) {
+			log.Printf("execute: maintenance: %v\n", err)
+		}
+	}
+
 	for _, po := range pos {
 		po.Player.Log("\nBookkeeping -----------------------------------------------------\n")
 	}
 	// bookkeeping
-	for _, cs := range e.CorSById {
+	for _, cs := range e.sortedCorS() {
 		cs.Log("%s:\n", cs.HullId)
-		// population changes
-		if cs.Kind == "ship" {
-			cs.Population.BirthsPriorTurn = 0
-		} else {
-			// TODO: create a standard of living metric and change rate to 0.25% ... 2.5%
-			birthRate := 0.0025 // 0.25% per year baseline
-			cs.Population.BirthsPriorTurn = int(float64(totalPop(cs)) * birthRate / 4)
-		}
-		cs.Population.ProfessionalQty = cs.pro.initial + cs.pro.created - cs.pro.destroyed
-		cs.Population.SoldierQty = cs.sol.initial + cs.sol.created - cs.sol.destroyed
-		cs.Population.UnskilledQty = cs.uns.initial + cs.uns.created - cs.uns.destroyed
-		cs.Population.ConstructionCrewQty = cs.cons.initial + cs.cons.created - cs.cons.destroyed
-		cs.Population.SpyTeamQty = cs.spy.initial + cs.spy.created - cs.spy.destroyed
-		cs.Population.UnemployedQty = cs.uem.initial + cs.uem.created - cs.uem.destroyed // + cs.Population.BirthsPriorTurn
-		cs.Population.NaturalDeathsPriorTurn = cs.nonCombatDeaths
-
-		// update fuel depot
-		foundFuel := false
-		for _, u := range cs.Inventory {
-			if u.Unit.Kind == "fuel" {
-				if foundFuel {
-					u.ActiveQty, u.StowedQty = 0, 0
-				} else {
-					foundFuel, u.ActiveQty, u.StowedQty = true, 0, cs.fuel.available()
-				}
-			}
+		// the labor pools are only loaded by the labor allocation or combat phases.
+		if cs.laborLoaded {
+			cs.Population.ProfessionalQty = cs.pro.operational + cs.pro.created - cs.pro.destroyed
+			cs.Population.SoldierQty = cs.sol.operational + cs.sol.created - cs.sol.destroyed
+			cs.Population.UnskilledQty = cs.uns.operational + cs.uns.created - cs.uns.destroyed
+			cs.Population.ConstructionCrewQty = cs.cons.operational + cs.cons.created - cs.cons.destroyed
+			cs.Population.SpyTeamQty = cs.spy.operational + cs.spy.created - cs.spy.destroyed
+			cs.Population.UnemployedQty = cs.uem.operational + cs.uem.created - cs.uem.destroyed
 		}
 
+		// units created or destroyed during the turn
+		cs.updateInventory()
+
 		// inventory changes
 		for _, group := range cs.FarmGroups {
 			var unit *InventoryUnit
@@ -301,18 +624,26 @@ func (e *Engine) Execute(pos []*PhaseOrders, phases ...string) error {
 			cs.Log("   factory group %d inventory %s stowed %d adding %d %d %d %d\n", group.No, group.Product.Code, unit.StowedQty, group.StageQty[0], group.StageQty[1], group.StageQty[2], group.StageQty[3])
 			group.StageQty[3] = 0
 		}
+
+		// population changes depend on the inventory left at the end of the turn
+		cs.Population.StandardOfLiving = standardOfLiving(cs)
+		births, deaths := populationGrowth(cs)
+		cs.Population.BirthsPriorTurn = births
+		cs.Population.NaturalDeathsPriorTurn = cs.nonCombatDeaths + deaths
 	}
+	e.emigration()
 
 	return nil
 }
 
-// ExecuteFuelAllocationPhase runs all the orders in the fuel allocation phase.
-func (e *Engine) ExecuteFuelAllocationPhase(pos []*PhaseOrders) (errs []error) {
+// ExecutePowerAllocationPhase runs all the orders in the power allocation phase.
+func (e *Engine) ExecutePowerAllocationPhase(pos []*PhaseOrders) (errs []error) {
 	for _, o := range pos {
-		o.Player.Log("\n\nFuel Allocation -------------------------------------------------\n")
+		o.Player.Log("\n\nPower Allocation ------------------------------------------------\n")
 	}
-	for _, cs := range e.CorSById {
+	for _, cs := range e.sortedCorS() {
 		fuelInitialization(cs, pos)
+		powerInitialization(cs)
 	}
 	return errs
 }
@@ -322,10 +653,10 @@ func (e *Engine) ExecuteLifeSupportPhase(pos []*PhaseOrders) (errs []error) {
 	for _, o := range pos {
 		o.Player.Log("\n\nLife Support ----------------------------------------------------\n")
 	}
-	for _, cors := range e.CorSById {
+	for _, cors := range e.sortedCorS() {
 		cors.lifeSupportInitialization(pos)
 	}
-	for _, cors := range e.CorSById {
+	for _, cors := range e.sortedCorS() {
 		cors.lifeSupportCheck()
 	}
 	return errs
@@ -336,7 +667,7 @@ func (e *Engine) ExecuteLaborAllocationPhase(pos []*PhaseOrders) (errs []error)
 	for _, o := range pos {
 		o.Player.Log("\n\nLabor Allocation ------------------------------------------------\n")
 	}
-	for _, cs := range e.CorSById {
+	for _, cs := range e.sortedCorS() {
 		laborInitialization(cs, pos)
 	}
 	return errs
@@ -347,7 +678,7 @@ func (e *Engine) ExecuteFarmProductionPhase(pos []*PhaseOrders) (errs []error) {
 	for _, o := range pos {
 		o.Player.Log("\n\nFarm Production -------------------------------------------------\n")
 	}
-	for _, cs := range e.CorSById {
+	for _, cs := range e.sortedCorS() {
 		if len(cs.FarmGroups) != 0 {
 			farmProduction(cs, pos)
 		}
@@ -360,7 +691,7 @@ func (e *Engine) ExecuteMineProductionPhase(pos []*PhaseOrders) (errs []error) {
 	for _, o := range pos {
 		o.Player.Log("\n\nMine Production -------------------------------------------------\n")
 	}
-	for _, cs := range e.CorSById {
+	for _, cs := range e.sortedCorS() {
 		if len(cs.MineGroups) != 0 {
 			mineProduction(cs, pos)
 		}
@@ -373,7 +704,7 @@ func (e *Engine) ExecuteFactoryProductionPhase(pos []*PhaseOrders) (errs []error
 	for _, o := range pos {
 		o.Player.Log("\n\nFactory Production ----------------------------------------------\n")
 	}
-	for _, cs := range e.CorSById {
+	for _, cs := range e.sortedCorS() {
 		if len(cs.FactoryGroups) != 0 {
 			factoryProduction(cs, pos)
 		}
@@ -381,20 +712,14 @@ func (e *Engine) ExecuteFactoryProductionPhase(pos []*PhaseOrders) (errs []error
 	return errs
 }
 
-// ExecuteCombatPhase runs all the orders in the combat phase.
-func (e *Engine) ExecuteCombatPhase(pos []*PhaseOrders) (errs []error) {
-	for _, o := range pos {
-		o.Player.Log("\n\nCombat ----------------------------------------------------------\n")
-		o.Player.Log("  Not Implemented!\n")
-	}
-	return errs
-}
-
 // ExecuteAssemblyPhase runs all the orders in the assembly phase.
 func (e *Engine) ExecuteAssemblyPhase(pos []*PhaseOrders) (errs []error) {
 	for _, o := range pos {
 		o.Player.Log("\n\nAssembly --------------------------------------------------------\n")
 		for _, order := range o.Assembly {
+			if o.skip(e, order) {
+				continue
+			}
 			if err := order.ConstructionCrew.Execute(e, o.Player); err != nil {
 				errs = append(errs, err)
 			}
@@ -494,7 +819,7 @@ func (o *AssembleFactoryGroupOrder) Execute(e *Engine, p *Player) error {
 	if !ok {
 		p.Log("           %s: no such unit %q\n", o.CorS, o.Unit)
 		return fmt.Errorf("no such unit %q", o.Unit)
-	} else if factory.TechLevel > cs.TechLevel {
+	} else if factory.TechLevel > techLevel(cs) {
 		p.Log("           %s: unit %q: invalid tech level\n", o.CorS, o.Unit)
 		return fmt.Errorf("invalid tech level %q", o.Product)
 	}
@@ -531,7 +856,7 @@ func (o *AssembleFactoryGroupOrder) Execute(e *Engine, p *Player) error {
 	if !ok {
 		p.Log("           %s: no such unit %q product %q\n", o.CorS, o.Unit, o.Product)
 		return fmt.Errorf("no such unit %q", o.Product)
-	} else if product.TechLevel > cs.TechLevel {
+	} else if product.TechLevel > techLevel(cs) {
 		p.Log("           %s: unit %q product %q: invalid tech level\n", o.CorS, o.Unit, o.Product)
 		return fmt.Errorf("invalid tech level %q", o.Product)
 	} else if product.Kind == "food" || product.Kind == "fuel" || product.Kind == "gold" || product.Kind == "metallics" || product.Kind == "non-metallics" {
@@ -644,7 +969,7 @@ func (o *AssembleFarmGroupOrder) Execute(e *Engine, p *Player) error {
 	if !ok {
 		p.Log("           %s: no such unit %q\n", o.CorS, o.Unit)
 		return fmt.Errorf("no such unit %q", o.Unit)
-	} else if farm.TechLevel > cs.TechLevel {
+	} else if farm.TechLevel > techLevel(cs) {
 		p.Log("           %s: unit %q: invalid tech level\n", o.CorS, o.Unit)
 		return fmt.Errorf("invalid tech level %q", o.Unit)
 	}
@@ -681,7 +1006,7 @@ func (o *AssembleFarmGroupOrder) Execute(e *Engine, p *Player) error {
 	if !ok {
 		p.Log("           %s: no such unit %q product %q\n", o.CorS, o.Unit, o.Product)
 		return fmt.Errorf("no such unit %q", o.Product)
-	} else if product.TechLevel > cs.TechLevel {
+	} else if product.TechLevel > techLevel(cs) {
 		p.Log("           %s: unit %q product %q: invalid tech level\n", o.CorS, o.Unit, o.Product)
 		return fmt.Errorf("invalid tech level %q", o.Product)
 	} else if product.Kind != "food" {
@@ -781,7 +1106,7 @@ func (o *AssembleMineGroupOrder) Execute(e *Engine, p *Player) error {
 	if !ok {
 		p.Log("           %s: no such unit %q\n", o.CorS, o.Unit)
 		return fmt.Errorf("no such unit %q", o.Unit)
-	} else if mine.TechLevel > cs.TechLevel {
+	} else if mine.TechLevel > techLevel(cs) {
 		p.Log("           %s: unit %q: invalid tech level\n", o.CorS, o.Unit)
 		return fmt.Errorf("invalid tech level %q", o.Unit)
 	}
@@ -938,6 +1263,9 @@ func (e *Engine) ExecuteControlPhase(pos []*PhaseOrders) (errs []error) {
 	for _, o := range pos {
 		o.Player.Log("\n\nControl ---------------------------------------------------------\n")
 		for _, order := range o.Control {
+			if o.skip(e, order) {
+				continue
+			}
 			if err := order.ControlColony.Execute(e, o.Player); err != nil {
 				errs = append(errs, err)
 			}
@@ -1047,14 +1375,3 @@ func (o *NameShipOrder) Execute(e *Engine, p *Player) error {
 	p.Log("  name %s: now named %q\n", o.Id, s.Name)
 	return nil
 }
-
-// ExecuteRetoolPhase runs all the orders in the retool phase.
-func (e *Engine) ExecuteRetoolPhase(pos []*PhaseOrders) (errs []error) {
-	for _, po := range pos {
-		if len(po.Retool) == 0 {
-			continue
-		}
-		log.Printf("execute: %s: retool\n", po.Player.Name)
-	}
-	return errs
-}
@@ -0,0 +1,50 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+import (
+	"testing"
+)
+
+func TestPhaseName(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		want string // empty if the phase is rejected
+	}{
+		{name: "power-allocation", want: "power-allocation"},
+		{name: "fuel-allocation", want: "power-allocation"},
+		{name: "maintenance", want: "maintenance"},
+		{name: "fuel"},
+		{name: "Combat"},
+		{name: ""},
+	} {
+		got, err := PhaseName(tc.name)
+		if tc.want == "" {
+			if err == nil {
+				t.Errorf("%q: want error: got %q", tc.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: want %q: got %v", tc.name, tc.want, err)
+		} else if tc.want != got {
+			t.Errorf("%q: want %q: got %q", tc.name, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+import (
+	"fmt"
+	"math"
+)
+
+// starvationRate is the fraction of the population that dies when a
+// colony or ship doesn't get any of the food it needs.
+const starvationRate = 0.25
+
+// unrestRate is the increase in the rebel percentage when a colony or
+// ship doesn't get any of the consumer goods or food it needs.
+const unrestRate = 0.1
+
+// ExecutePayPhase runs all the orders in the pay phase.
+// After the orders are applied, every colony and ship pays its population in consumer goods.
+// Shortfalls increase the rebel percentage.
+func (e *Engine) ExecutePayPhase(pos []*PhaseOrders) (errs []error) {
+	for _, o := range pos {
+		o.Player.Log("\n\nPay -------------------------------------------------------------\n")
+		for _, order := range o.Pay {
+			if o.skip(e, order) {
+				continue
+			}
+			if err := order.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	for _, cs := range e.sortedCorS() {
+		needed := cs.Pay.totalPay(cs.Population, "PRO") + cs.Pay.totalPay(cs.Population, "SLD") + cs.Pay.totalPay(cs.Population, "USK")
+		if needed == 0 {
+			continue
+		}
+		paid := consume(cs, "consumer-goods", needed)
+		cs.Log("  pay %s: needed %d consumer goods: paid %d\n", cs.HullId, needed, paid)
+		cs.payShortfall = float64(needed-paid) / float64(needed)
+		if shortfall := cs.payShortfall; shortfall > 0 {
+			cs.Population.RebelPct = math.Min(1, cs.Population.RebelPct+shortfall*unrestRate)
+			cs.Log("      %s: shortfall %6.2f%%: rebels %6.2f%%\n", cs.HullId, shortfall*100, cs.Population.RebelPct*100)
+		}
+	}
+	return errs
+}
+
+// ExecuteRationPhase runs all the orders in the ration phase.
+// After the orders are applied, every colony and ship feeds its population.
+// Shortfalls cause starvation and increase the rebel percentage.
+func (e *Engine) ExecuteRationPhase(pos []*PhaseOrders) (errs []error) {
+	for _, o := range pos {
+		o.Player.Log("\n\nRations ---------------------------------------------------------\n")
+		for _, order := range o.Ration {
+			if o.skip(e, order) {
+				continue
+			}
+			if err := order.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	for _, cs := range e.sortedCorS() {
+		needed := cs.Rations.totalRations(cs.Population, "PRO") + cs.Rations.totalRations(cs.Population, "SLD") + cs.Rations.totalRations(cs.Population, "USK") + cs.Rations.totalRations(cs.Population, "UEM")
+		if needed == 0 {
+			continue
+		}
+		fed := consume(cs, "food", needed)
+		cs.Log("  ration %s: needed %d food: fed %d\n", cs.HullId, needed, fed)
+		cs.rationShortfall = float64(needed-fed) / float64(needed)
+		if shortfall := cs.rationShortfall; shortfall > 0 {
+			// deaths are booked against the labor pools, so they only
+			// happen when the labor allocation phase has loaded them.
+			deaths := int(shortfall * starvationRate * float64(totalPop(cs)))
+			killProportionally(cs, deaths)
+			cs.nonCombatDeaths += deaths
+			cs.Population.RebelPct = math.Min(1, cs.Population.RebelPct+shortfall*unrestRate)
+			cs.Log("         %s: shortfall %6.2f%%: starved %d: rebels %6.2f%%\n", cs.HullId, shortfall*100, deaths, cs.Population.RebelPct*100)
+		}
+	}
+	return errs
+}
+
+// Execute sets the pay rate for a class of population on a colony or ship.
+// Will fail if the colony or ship is not controlled by the player.
+func (o *PayOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	p.Log("  pay %s: %s %6.2f%%\n", o.CorS, o.Class, o.Pct*100)
+	cs, err := findControlled(e, p, "pay", o.CorS)
+	if err != nil {
+		return err
+	} else if o.Pct < 0 {
+		p.Log("      %s: invalid pay rate\n", o.CorS)
+		return fmt.Errorf("invalid pay rate %f", o.Pct)
+	}
+	switch o.Class {
+	case "professional":
+		cs.Pay.ProfessionalPct = o.Pct
+	case "soldier":
+		cs.Pay.SoldierPct = o.Pct
+	case "unskilled":
+		cs.Pay.UnskilledPct = o.Pct
+	default:
+		p.Log("      %s: no such population class %q\n", o.CorS, o.Class)
+		return fmt.Errorf("no such population class %q", o.Class)
+	}
+	return nil
+}
+
+// Execute sets the ration rate for a class of population on a colony or ship.
+// Will fail if the colony or ship is not controlled by the player.
+func (o *RationOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	p.Log("  ration %s: %s %6.2f%%\n", o.CorS, o.Class, o.Pct*100)
+	cs, err := findControlled(e, p, "ration", o.CorS)
+	if err != nil {
+		return err
+	} else if o.Pct < 0 {
+		p.Log("         %s: invalid ration rate\n", o.CorS)
+		return fmt.Errorf("invalid ration rate %f", o.Pct)
+	}
+	switch o.Class {
+	case "professional":
+		cs.Rations.ProfessionalPct = o.Pct
+	case "soldier":
+		cs.Rations.SoldierPct = o.Pct
+	case "unskilled":
+		cs.Rations.UnskilledPct = o.Pct
+	case "unemployed":
+		cs.Rations.UnemployedPct = o.Pct
+	default:
+		p.Log("         %s: no such population class %q\n", o.CorS, o.Class)
+		return fmt.Errorf("no such population class %q", o.Class)
+	}
+	return nil
+}
+
+// findControlled returns the colony or ship with the given id.
+// Will fail if it doesn't exist or is controlled by another player.
+func findControlled(e *Engine, p *Player, verb, id string) (*CorS, error) {
+	cs, ok := e.findColony(id)
+	if !ok {
+		cs, ok = e.findShip(id)
+	}
+	if !ok || (cs.ControlledBy != nil && cs.ControlledBy != p) {
+		p.Log("  %s %s: no such colony or ship\n", verb, id)
+		return nil, fmt.Errorf("no such colony or ship %q", id)
+	}
+	return cs, nil
+}
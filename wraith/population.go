@@ -0,0 +1,163 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+import (
+	"math"
+)
+
+const (
+	// minBirthRate and maxBirthRate are the yearly birth rates for the worst and best standards of living
+	minBirthRate, maxBirthRate = 0.0025, 0.025
+	// minDeathRate and maxDeathRate are the yearly natural death rates for the best and worst standards of living
+	minDeathRate, maxDeathRate = 0.005, 0.025
+	// artificialHabitability is the habitability score for colonies and ships that aren't open to the air
+	artificialHabitability = 0.4
+	// consumerGoodsReserve is the number of consumer goods per population unit needed for a full consumer goods score
+	consumerGoodsReserve = 0.1
+	// emigrationRate is the share of the unemployed that leave a colony each turn for each point
+	// the colony's standard of living falls behind the nation's best colony
+	emigrationRate = 0.1
+	// emigrationThreshold is how far a colony must fall behind before anyone leaves
+	emigrationThreshold = 0.1
+)
+
+// standardOfLiving scores the colony or ship from pay, rations, consumer goods, and habitability.
+// Full pay and rations on a world with a habitability of 25 and a reserve of consumer goods scores 1.
+// Generous pay and rations can push the score above 1.
+func standardOfLiving(cs *CorS) float64 {
+	pop := cs.Population
+
+	payScore, paid := 1.0, pop.ProfessionalQty+pop.SoldierQty+pop.UnskilledQty
+	if paid != 0 {
+		payScore = (cs.Pay.ProfessionalPct*float64(pop.ProfessionalQty) +
+			cs.Pay.SoldierPct*float64(pop.SoldierQty) +
+			cs.Pay.UnskilledPct*float64(pop.UnskilledQty)) / float64(paid)
+	}
+	payScore = math.Min(1.5, payScore*(1-cs.payShortfall))
+
+	rationScore, fed := 1.0, paid+pop.UnemployedQty
+	if fed != 0 {
+		rationScore = (cs.Rations.ProfessionalPct*float64(pop.ProfessionalQty) +
+			cs.Rations.SoldierPct*float64(pop.SoldierQty) +
+			cs.Rations.UnskilledPct*float64(pop.UnskilledQty) +
+			cs.Rations.UnemployedPct*float64(pop.UnemployedQty)) / float64(fed)
+	}
+	rationScore = math.Min(1.5, rationScore*(1-cs.rationShortfall))
+
+	goodsScore, goods := 1.0, 0
+	for _, u := range cs.Inventory {
+		if u.Unit.Kind == "consumer-goods" {
+			goods += u.ActiveQty + u.StowedQty
+		}
+	}
+	if fed != 0 {
+		goodsScore = math.Min(1, float64(goods)/(consumerGoodsReserve*float64(fed)))
+	}
+
+	habitabilityScore := artificialHabitability
+	if (cs.Kind == "open" || cs.Kind == "surface") && cs.Planet != nil {
+		habitabilityScore = float64(cs.Planet.HabitabilityNo) / 25
+	}
+
+	return 0.35*payScore + 0.35*rationScore + 0.1*goodsScore + 0.2*habitabilityScore
+}
+
+// populationGrowth applies births and natural deaths for the turn using the standard of living.
+// Births join the unemployed. Ships don't have births.
+// Returns the number of births and natural deaths.
+func populationGrowth(cs *CorS) (births, deaths int) {
+	sol := math.Max(0, math.Min(1, cs.Population.StandardOfLiving))
+	total := float64(cs.Population.Total())
+
+	if cs.Kind != "ship" {
+		births = int(total * (minBirthRate + (maxBirthRate-minBirthRate)*sol) / 4)
+	}
+	deaths = int(total * (maxDeathRate - (maxDeathRate-minDeathRate)*sol) / 4)
+
+	cs.Population.UnemployedQty += births
+	killPopulation(&cs.Population, deaths)
+	cs.Log("   standard of living %6.3f births %d natural deaths %d\n", cs.Population.StandardOfLiving, births, deaths)
+
+	return births, deaths
+}
+
+// killPopulation removes population units from each class in proportion to its size.
+func killPopulation(pop *Population, n int) {
+	total := pop.Total()
+	if n <= 0 || total == 0 {
+		return
+	} else if n > total {
+		n = total
+	}
+	classes := []*int{&pop.UnemployedQty, &pop.UnskilledQty, &pop.SoldierQty, &pop.ProfessionalQty}
+	killed := 0
+	for _, qty := range classes {
+		k := n * *qty / total
+		*qty, killed = *qty-k, killed+k
+	}
+	// rounding leaves a few more to remove, starting with the unemployed
+	for _, qty := range classes {
+		if killed == n {
+			break
+		}
+		k := n - killed
+		if *qty < k {
+			k = *qty
+		}
+		*qty, killed = *qty-k, killed+k
+	}
+}
+
+// emigration moves unemployed population from a nation's colonies to the colony
+// with the nation's best standard of living.
+func (e *Engine) emigration() {
+	nations := make(map[*Nation][]*CorS)
+	for _, cs := range e.sortedCorS() {
+		cs.Population.ImmigrantsPriorTurn, cs.Population.EmigrantsPriorTurn = 0, 0
+		if cs.Kind == "ship" || cs.ControlledBy == nil || cs.ControlledBy.MemberOf == nil {
+			continue
+		}
+		nations[cs.ControlledBy.MemberOf] = append(nations[cs.ControlledBy.MemberOf], cs)
+	}
+
+	for _, colonies := range nations {
+		best := colonies[0]
+		for _, cs := range colonies[1:] {
+			if cs.Population.StandardOfLiving > best.Population.StandardOfLiving {
+				best = cs
+			}
+		}
+		for _, cs := range colonies {
+			gap := best.Population.StandardOfLiving - cs.Population.StandardOfLiving
+			if cs == best || gap < emigrationThreshold {
+				continue
+			}
+			n := int(float64(cs.Population.UnemployedQty) * emigrationRate * math.Min(1, gap))
+			if n <= 0 {
+				continue
+			}
+			cs.Population.UnemployedQty -= n
+			cs.Population.EmigrantsPriorTurn += n
+			best.Population.UnemployedQty += n
+			best.Population.ImmigrantsPriorTurn += n
+			cs.Log("   %s: %d emigrated to %s\n", cs.HullId, n, best.HullId)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+// energy is measured in the same units as fuel; one unit of energy does the
+// work of one unit of fuel. energy can't be stored, so any energy that isn't
+// used during the turn is lost.
+
+const (
+	// energyPerPlantPerTechLevel is the energy a single power plant produces each turn at each tech level.
+	energyPerPlantPerTechLevel = 4
+	// energyPerArrayPerTechLevel is the energy a single solar array produces each turn, at each tech level, in full sunlight.
+	energyPerArrayPerTechLevel = 1
+)
+
+// solarIntensity returns the strength of the sunlight at the colony or ship,
+// relative to the inner orbits of an A class star. only orbital colonies
+// and ships can collect sunlight.
+func solarIntensity(cs *CorS) float64 {
+	if !(cs.Kind == "orbital" || cs.Kind == "ship") || cs.Planet == nil || cs.Planet.Star == nil {
+		return 0
+	}
+	var brightness float64
+	switch cs.Planet.Star.Kind {
+	case "A":
+		brightness = 1
+	case "B":
+		brightness = 0.75
+	case "C":
+		brightness = 0.5
+	case "D":
+		brightness = 0.25
+	}
+	// sunlight is steady through the fifth orbit and falls off beyond it
+	if cs.Planet.OrbitNo > 5 {
+		return brightness * 5 / float64(cs.Planet.OrbitNo)
+	}
+	return brightness
+}
+
+// isPowerUnit returns true if the unit generates energy.
+func isPowerUnit(u *Unit) bool {
+	return u.Kind == "power-plant" || u.Kind == "solar-array"
+}
+
+// energyOutput returns the energy produced in one turn by qty units of a power plant or solar array.
+func energyOutput(cs *CorS, u *Unit, qty int) int {
+	switch u.Kind {
+	case "power-plant":
+		return qty * u.TechLevel * energyPerPlantPerTechLevel
+	case "solar-array":
+		return int(float64(qty*u.TechLevel*energyPerArrayPerTechLevel) * solarIntensity(cs))
+	}
+	return 0
+}
+
+// powerInitialization runs the active power plants and solar arrays and books
+// the energy they produce in the power pool. power plants run on as much fuel
+// as is available; they can't run on the energy they produce.
+func powerInitialization(cs *CorS) {
+	cs.Log("  %12.3f%% solar intensity\n", 100*solarIntensity(cs))
+	energy := 0
+	for _, units := range []InventoryUnits{cs.Hull, cs.Inventory} {
+		for _, u := range units {
+			if !isPowerUnit(u.Unit) || u.ActiveQty == 0 {
+				continue
+			}
+			qty := u.ActiveQty
+			if !isZero(u.Unit.FuelPerUnitPerTurn) {
+				if limit := int(float64(fuelOnHand(cs)) / u.Unit.FuelPerUnitPerTurn); limit < qty {
+					qty = limit
+				}
+			}
+			fuel := removeFuel(cs, u.Unit.fuelUsed(qty))
+			output := energyOutput(cs, u.Unit, qty)
+			energy += output
+			cs.Log("  %-7s %13d of %13d active  %13d FUEL  %13d ENERGY\n",
+				u.Unit.Code, qty, u.ActiveQty, fuel, output)
+		}
+	}
+	cs.pwr.operational += energy
+	cs.Log("  %13d ENERGY available for use\n\n", energy)
+}
+
+// burnFuel uses qty units of energy and fuel.
+// energy is used before fuel since energy that isn't used is lost.
+func burnFuel(cs *CorS, qty int) {
+	if energy := cs.pwr.operational - cs.pwr.allocated; energy < qty {
+		cs.pwr.allocated += energy
+		qty -= energy
+	} else {
+		cs.pwr.allocated += qty
+		qty = 0
+	}
+	removeFuel(cs, qty)
+}
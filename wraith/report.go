@@ -101,10 +101,22 @@ func (e *Engine) Report(w io.Writer, playerIds ...int) error {
 			_, _ = p.Fprintf(w, "  Crew/Team________  Units___________\n")
 			_, _ = p.Fprintf(w, "  Construction Crew  %16d\n", cs.Population.ConstructionCrewQty)
 			_, _ = p.Fprintf(w, "  Spy Team           %16d\n", cs.Population.SpyTeamQty)
+			_, _ = p.Fprintf(w, "  Automation         %16d\n", automationCapacity(cs))
+			if len(cs.Population.Training) != 0 {
+				_, _ = p.Fprintf(w, "\n")
+				_, _ = p.Fprintf(w, "  In Training______  Units___________  Turns_Left\n")
+				for _, t := range cs.Population.Training {
+					_, _ = p.Fprintf(w, "  %-17s  %16d  %10d\n", t.Class, t.Qty, t.TurnsLeft)
+				}
+			}
 			_, _ = p.Fprintf(w, "\n")
 			_, _ = p.Fprintf(w, "  Changes__________  Population_Units\n")
 			_, _ = p.Fprintf(w, "  Births             %16d\n", cs.Population.BirthsPriorTurn)
 			_, _ = p.Fprintf(w, "  Non-Combat Deaths  %16d\n", cs.Population.NaturalDeathsPriorTurn)
+			_, _ = p.Fprintf(w, "  Immigrants         %16d\n", cs.Population.ImmigrantsPriorTurn)
+			_, _ = p.Fprintf(w, "  Emigrants          %16d\n", cs.Population.EmigrantsPriorTurn)
+			_, _ = p.Fprintf(w, "  Standard of Living %16.3f\n", cs.Population.StandardOfLiving)
+			_, _ = p.Fprintf(w, "  Rebels             %15.3f%%\n", cs.Population.RebelPct*100)
 
 			_, _ = p.Fprintf(w, "\n")
 			_, _ = p.Fprintf(w, "  Hull and Systems ----------------------------------------------------------------------------\n")
@@ -144,6 +156,24 @@ func (e *Engine) Report(w io.Writer, playerIds ...int) error {
 				_, _ = p.Fprintf(w, "   Totals  ------------  %11d  %11d  %11d\n", operMass, operVolume, fuOper)
 			}
 
+			_, _ = p.Fprintf(w, "\n")
+			_, _ = p.Fprintf(w, "  Power ---------------------------------------------------------------------------------------\n")
+			_, _ = p.Fprintf(w, "  Star: %-2s  Orbit: %2d  Solar Intensity: %7.3f%%\n", cs.Planet.Star.Kind, cs.Planet.OrbitNo, 100*solarIntensity(cs))
+			_, _ = p.Fprintf(w, "  Item-TL   Operational  FUEL/Turn__  Energy/Turn\n")
+			fuPower, enPower := 0, 0
+			for _, units := range []InventoryUnits{cs.Hull, cs.Inventory} {
+				for _, item := range units {
+					if !isPowerUnit(item.Unit) {
+						continue
+					}
+					fuelPerTurn, energyPerTurn := item.Unit.fuelUsed(item.ActiveQty), energyOutput(cs, item.Unit, item.ActiveQty)
+					fuPower, enPower = fuPower+fuelPerTurn, enPower+energyPerTurn
+					_, _ = p.Fprintf(w, "  %-7s  %12d  %11d  %11d\n", item.Unit.Code, item.ActiveQty, fuelPerTurn, energyPerTurn)
+				}
+			}
+			_, _ = p.Fprintf(w, "  -------  ------------  -----------  -----------\n")
+			_, _ = p.Fprintf(w, "   Totals  ------------  %11d  %11d\n", fuPower, enPower)
+
 			availSUs := 0
 			cargoMass, cargoVolume, suCargo := 0, 0, 0
 			_, _ = p.Fprintf(w, "\n")
@@ -196,12 +226,17 @@ func (e *Engine) Report(w io.Writer, playerIds ...int) error {
 			_, _ = p.Fprintf(w, "\n")
 			_, _ = p.Fprintf(w, "  Mining ---------------------------------------------------------------------------------------------------------\n")
 			for _, group := range cs.MineGroups {
-				_, _ = p.Fprintf(w, "  Group: %2d  Deposit: DP%-3d   Yield %6.3f%%    Remaining: %13d %-5s\n", group.No, group.Deposit.No, 100*group.Deposit.YieldPct, group.Deposit.RemainingQty, group.Deposit.Product.Code)
+				_, _ = p.Fprintf(w, "  Group: %2d  Deposit: DP%-3d   Yield %6.3f%%    Remaining: %13d %-5s\n", group.No, group.Deposit.No, 100*depositYield(group.Deposit), group.Deposit.RemainingQty, group.Deposit.Product.Code)
 				fuelPerTurn := int(math.Ceil(float64(group.Unit.ActiveQty) * group.Unit.Unit.FuelPerUnitPerTurn))
 				extractPerTurn := group.Unit.ActiveQty * 100 * group.Unit.Unit.TechLevel / 4
+				if group.Deposit.RemainingQty <= 0 {
+					extractPerTurn = 0
+				} else if extractPerTurn > group.Deposit.RemainingQty {
+					extractPerTurn = group.Deposit.RemainingQty
+				}
 				proLabor, uskLabor := 1*group.Unit.ActiveQty, 3*group.Unit.ActiveQty
 				_, _ = p.Fprintf(w, "     Input:  Mines__  Quantity_____  Professionals  Unskilled____  FUEL/Turn____  Extract/Turn_  Yield/Turn___\n")
-				_, _ = p.Fprintf(w, "             %-7s  %13d  %13d  %13d  %13d  %13d  %13d\n", group.Unit.Unit.Code, group.Unit.ActiveQty, proLabor, uskLabor, fuelPerTurn, extractPerTurn, int(math.Ceil(float64(extractPerTurn)*group.Deposit.YieldPct)))
+				_, _ = p.Fprintf(w, "             %-7s  %13d  %13d  %13d  %13d  %13d  %13d\n", group.Unit.Unit.Code, group.Unit.ActiveQty, proLabor, uskLabor, fuelPerTurn, extractPerTurn, int(math.Ceil(float64(extractPerTurn)*depositYield(group.Deposit))))
 				_, _ = p.Fprintf(w, "    Output:  Unit___  Stage_1______  Stage_2______  Stage_3______\n")
 				_, _ = p.Fprintf(w, "             %-7s  %13d  %13d  %13d\n", group.Deposit.Product.Code, group.StageQty[0], group.StageQty[1], group.StageQty[2])
 			}
@@ -221,53 +256,67 @@ func (e *Engine) Report(w io.Writer, playerIds ...int) error {
 				_, _ = p.Fprintf(w, "    Output:  Unit___  Stage_1______  Stage_2______  Stage_3______\n")
 				_, _ = p.Fprintf(w, "             %-7s  %13d  %13d  %13d\n", group.Product.Code, group.StageQty[0], group.StageQty[1], group.StageQty[2])
 			}
-
-			_, _ = p.Fprintf(w, "\n")
-			_, _ = p.Fprintf(w, "  Espionage --------------------------------------------------------------------\n")
-			_, _ = p.Fprintf(w, "  No activity.\n")
 		}
 
 		_, _ = p.Fprintf(w, "\n------------------------------------------------------------------------------\n")
 		_, _ = p.Fprintf(w, "Surveys\n")
-		starMap := make(map[int]*Star)
-		for _, cs := range player.Colonies {
-			starMap[cs.Planet.Star.Id] = cs.Planet.Star
-		}
-		for _, cs := range player.Ships {
-			starMap[cs.Planet.Star.Id] = cs.Planet.Star
-		}
-		var stars Stars
-		for _, star := range starMap {
-			stars = append(stars, star)
+		var surveys Surveys
+		if player.MemberOf != nil {
+			for _, survey := range player.MemberOf.Surveys {
+				surveys = append(surveys, survey)
+			}
 		}
-		sort.Sort(stars)
-		for _, star := range stars {
-			_, _ = p.Fprintf(w, "     Star: %s%s\n", star.System.Coords.String(), star.Sequence)
-			for _, planet := range star.Planets {
-				if planet == nil {
-					continue
-				}
-				var habNo string
-				if planet.HabitabilityNo > 0 {
-					habNo = fmt.Sprintf("Habitability: %2d", planet.HabitabilityNo)
-				}
-				_, _ = p.Fprintf(w, "   Planet: %-13s    Kind: %-14s   %-17s\n", fmt.Sprintf("%s%s#%d", star.System.Coords.String(), star.Sequence, planet.OrbitNo), planet.Kind, habNo)
-				for _, r := range planet.Deposits {
-					_, _ = p.Fprintf(w, "           Deposit: %2d   %-6s   Yield: %7.3f%%   MUs remaining: %12d\n", r.No, r.Product.Code, r.YieldPct*100, r.RemainingQty)
-				}
+		sort.Sort(surveys)
+		var star *Star
+		for _, survey := range surveys {
+			planet := survey.Planet
+			if planet.Star != star {
+				star = planet.Star
+				_, _ = p.Fprintf(w, "     Star: %s%s\n", star.System.Coords.String(), star.Sequence)
+			}
+			var habNo string
+			if survey.HabitabilityNo > 0 {
+				habNo = fmt.Sprintf("Habitability: %2d", survey.HabitabilityNo)
+			}
+			_, _ = p.Fprintf(w, "   Planet: %-13s    Kind: %-14s   %-17s   Surveyed: %d/%d\n", planet.String(), survey.Kind, habNo, survey.Year, survey.Quarter)
+			for _, r := range survey.Deposits {
+				_, _ = p.Fprintf(w, "           Deposit: %2d   %-6s   Yield: %7.3f%%   MUs remaining: %12d\n", r.No, r.Product.Code, r.YieldPct*100, r.RemainingQty)
 			}
 		}
 
 		_, _ = p.Fprintf(w, "\nMarket Report ---------------------------------------------------------------------\n")
-		_, _ = p.Fprintf(w, "  News: *** 6 years ago, scientists discovered a signal broadcasting from the 10th\n")
-		_, _ = p.Fprintf(w, "        orbit. 4 years ago, they decoded the message and recovered plans for an\n")
-		_, _ = p.Fprintf(w, "        in-system engine (the \"space-drive\") and a faster-than-light engine\n")
-		_, _ = p.Fprintf(w, "        (the \"hyper-drive\"). Work on both has recently completed.\n")
-		_, _ = p.Fprintf(w, "  News: *** Moments after the hyper-drive was successfully tested in the orbital\n")
-		_, _ = p.Fprintf(w, "        colony, the broadcast from the 10th orbit stopped.\n")
+		if len(e.Market) == 0 {
+			_, _ = p.Fprintf(w, "  No activity.\n")
+		} else {
+			_, _ = p.Fprintf(w, "  Unit___  Bid Qty______  Ask Qty______  Volume_______  Low Price__  High Price_  Avg Price____\n")
+			for _, r := range e.Market {
+				_, _ = p.Fprintf(w, "  %-7s  %13d  %13d  %13d  %11d  %11d  %13.2f\n", r.Unit.Code, r.BidQty, r.AskQty, r.Volume, r.LowPrice, r.HighPrice, r.AvgPrice)
+			}
+		}
 
 		_, _ = p.Fprintf(w, "\nCombat Report ---------------------------------------------------------------------\n")
-		_, _ = p.Fprintf(w, "  No activity.\n")
+		if len(player.CombatReport) == 0 {
+			_, _ = p.Fprintf(w, "  No activity.\n")
+		}
+		for _, line := range player.CombatReport {
+			_, _ = p.Fprintf(w, "  %s\n", line)
+		}
+
+		_, _ = p.Fprintf(w, "\nEspionage Report ------------------------------------------------------------------\n")
+		if len(player.EspionageReport) == 0 {
+			_, _ = p.Fprintf(w, "  No activity.\n")
+		}
+		for _, line := range player.EspionageReport {
+			_, _ = p.Fprintf(w, "  %s\n", line)
+		}
+
+		_, _ = p.Fprintf(w, "\nUnrest Report ---------------------------------------------------------------------\n")
+		if len(player.UnrestReport) == 0 {
+			_, _ = p.Fprintf(w, "  No activity.\n")
+		}
+		for _, line := range player.UnrestReport {
+			_, _ = p.Fprintf(w, "  %s\n", line)
+		}
 	}
 
 	return nil
@@ -0,0 +1,143 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	// maxResearchLevel is the highest tech level or skill level that can be researched
+	maxResearchLevel = 10
+	// researchCostPerLevel is the number of research points needed for each level.
+	// raising a level costs this times the new level.
+	researchCostPerLevel = 1_000
+	// researchPointsPerUnit is the number of points generated by each research unit per tech level
+	researchPointsPerUnit = 1
+	// specialityDiscount is the share of the cost paid when researching the nation's speciality
+	specialityDiscount = 0.75
+)
+
+// ExecuteResearchPhase runs all the orders in the research phase.
+// Research units on every ship and colony add points to their nation's pool,
+// then the orders spend the pool.
+func (e *Engine) ExecuteResearchPhase(pos []*PhaseOrders) (errs []error) {
+	for _, o := range pos {
+		o.Player.Log("\n\nResearch --------------------------------------------------------\n")
+	}
+	for _, cs := range e.sortedCorS() {
+		if cs.ControlledBy == nil || cs.ControlledBy.MemberOf == nil {
+			continue
+		}
+		points := 0
+		for _, u := range operationalUnits(cs, "research") {
+			points += u.operational.available() * u.Unit.TechLevel * researchPointsPerUnit
+		}
+		if points != 0 {
+			cs.ControlledBy.MemberOf.ResearchPointsPool += points
+			cs.Log("  research %s: generated %d research points\n", cs.HullId, points)
+		}
+	}
+
+	for _, o := range pos {
+		for _, order := range o.Research {
+			if o.skip(e, order) {
+				continue
+			}
+			if err := order.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// Execute spends the nation's research points to raise its tech level or a skill by one level.
+// Researching the nation's speciality costs less.
+// Will fail if the player doesn't control the nation or the pool doesn't have enough points.
+func (o *ResearchOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	p.Log("  research %s\n", o.Target)
+	n := p.MemberOf
+	if n == nil || n.ControlledBy != p {
+		p.Log("           %s: only the ruler of a nation may spend research points\n", o.Target)
+		return fmt.Errorf("%s: player does not control nation", o.Target)
+	}
+	level, ok := researchLevel(n, o.Target)
+	if !ok {
+		p.Log("           %s: no such tech-level or skill\n", o.Target)
+		return fmt.Errorf("no such research target %q", o.Target)
+	} else if *level >= maxResearchLevel {
+		p.Log("           %s: already at maximum level %d\n", o.Target, *level)
+		return fmt.Errorf("%s: already at maximum level", o.Target)
+	}
+
+	cost := researchCostPerLevel * (*level + 1)
+	if strings.EqualFold(n.Speciality, o.Target) {
+		cost = int(math.Ceil(float64(cost) * specialityDiscount))
+	}
+	p.Log("           %s: %-20s  %12d requested  %13d available\n", o.Target, "research points", cost, n.ResearchPointsPool)
+	if n.ResearchPointsPool < cost {
+		p.Log("           %s: not enough research points\n", o.Target)
+		return fmt.Errorf("%s: not enough research points", o.Target)
+	}
+	n.ResearchPointsPool -= cost
+	*level = *level + 1
+	p.Log("           %s: raised to level %d\n", o.Target, *level)
+
+	return nil
+}
+
+// researchLevel returns a pointer to the nation's tech level or skill named by the target.
+func researchLevel(n *Nation, target string) (*int, bool) {
+	switch strings.ToLower(target) {
+	case "tech-level":
+		return &n.TechLevel, true
+	case "biology":
+		return &n.Skills.Biology, true
+	case "bureaucracy":
+		return &n.Skills.Bureaucracy, true
+	case "gravitics":
+		return &n.Skills.Gravitics, true
+	case "life-support":
+		return &n.Skills.LifeSupport, true
+	case "manufacturing":
+		return &n.Skills.Manufacturing, true
+	case "military":
+		return &n.Skills.Military, true
+	case "mining":
+		return &n.Skills.Mining, true
+	case "shields":
+		return &n.Skills.Shields, true
+	}
+	return nil, false
+}
+
+// techLevel returns the highest tech level of units that the ship or colony can assemble or produce.
+// That is the higher of its own tech level and the tech level its nation has researched.
+func techLevel(cs *CorS) int {
+	if cs.ControlledBy != nil && cs.ControlledBy.MemberOf != nil && cs.ControlledBy.MemberOf.TechLevel > cs.TechLevel {
+		return cs.ControlledBy.MemberOf.TechLevel
+	}
+	return cs.TechLevel
+}
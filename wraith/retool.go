@@ -0,0 +1,207 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// retoolingTonnesPerCrew is the mass of units that a single construction
+// crew can retool in a turn. It matches the rate for assembling units.
+const retoolingTonnesPerCrew = 500
+
+// ExecuteRetoolPhase runs all the orders in the retool phase.
+func (e *Engine) ExecuteRetoolPhase(pos []*PhaseOrders) (errs []error) {
+	for _, o := range pos {
+		o.Player.Log("\n\nRetool ----------------------------------------------------------\n")
+		for _, order := range o.Retool {
+			if o.skip(e, order) {
+				continue
+			}
+			if err := order.FactoryGroup.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+			if err := order.MiningGroup.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// Execute switches a factory group to a new product.
+// Finished units are salvaged as the old product, but work in progress is scrapped.
+// The group is idle for a turn while it retools.
+// Will fail if the colony or ship is not controlled by the player or there aren't enough construction crews.
+func (o *RetoolFactoryGroupOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	p.Log("  retool %s: %s %s\n", o.CorS, o.Group, o.Product)
+	cs, err := findControlled(e, p, "retool", o.CorS)
+	if err != nil {
+		return err
+	}
+	var fg *FactoryGroup
+	for _, group := range cs.FactoryGroups {
+		if o.Group == fmt.Sprintf("FG%d", group.No) {
+			fg = group
+			break
+		}
+	}
+	if fg == nil {
+		p.Log("         %s: no such factory group %q\n", o.CorS, o.Group)
+		return fmt.Errorf("no such factory group %q", o.Group)
+	}
+
+	// fetch the product from the order.
+	product, ok := unitFromString(e, o.Product)
+	if !ok {
+		p.Log("         %s: no such product %q\n", o.CorS, o.Product)
+		return fmt.Errorf("no such unit %q", o.Product)
+	} else if product.TechLevel > techLevel(cs) {
+		p.Log("         %s: product %q: invalid tech level\n", o.CorS, o.Product)
+		return fmt.Errorf("invalid tech level %q", o.Product)
+	} else if product.Kind == "food" || product.Kind == "fuel" || product.Kind == "gold" || product.Kind == "metallics" || product.Kind == "non-metallics" {
+		p.Log("         %s: factories can not produce %q\n", o.CorS, o.Product)
+		return fmt.Errorf("invalid product %q", o.Product)
+	} else if product.Id == fg.Product.Id {
+		p.Log("         %s: %s: already producing %s\n", o.CorS, o.Group, product.Name)
+		return nil
+	}
+	for _, group := range cs.FactoryGroups {
+		if group.Product.Id == product.Id {
+			p.Log("         %s: group %2d is already producing %s\n", o.CorS, group.No, product.Name)
+			return fmt.Errorf("%q: already produced by group %d", o.Product, group.No)
+		}
+	}
+
+	// allocate labor. 1 CON per 500 tonnes of factory units.
+	mass := 0.0
+	for _, u := range fg.Units {
+		mass += float64(u.ActiveQty) * u.Unit.MassPerUnit
+	}
+	consRequested := int(math.Ceil(mass / retoolingTonnesPerCrew))
+	p.Log("         %s: %-20s  %12d requested  %13d available\n", cs.HullId, "construction-crew", consRequested, availableCon(cs))
+	if availableCon(cs) < consRequested {
+		p.Log("         %s: not enough CON to retool %s\n", o.CorS, o.Group)
+		return fmt.Errorf("%s: not enough cons available", o.CorS)
+	}
+	cs.cons.allocated += consRequested
+
+	// salvage the finished units and scrap the rest of the pipeline
+	stow(cs, fg.Product, fg.StageQty[3])
+	p.Log("         %s: %s: salvaged %d %s: scrapped %d in progress\n", o.CorS, o.Group, fg.StageQty[3], fg.Product.Name, fg.StageQty[0]+fg.StageQty[1]+fg.StageQty[2])
+	fg.Product, fg.StageQty, fg.IdleTurns = product, [4]int{}, 1
+
+	p.Log("         %s: %s: retooled to produce %s\n", o.CorS, o.Group, product.Name)
+	return nil
+}
+
+// Execute points a mine group at a new deposit on the same planet.
+// Finished ore is salvaged as the old product, but work in progress is scrapped.
+// The group is idle for a turn while it retools.
+// Will fail if the colony is not controlled by the player, the deposit is controlled
+// by another colony, or there aren't enough construction crews.
+func (o *RetoolMiningGroupOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	p.Log("  retool %s: %s %s\n", o.CorS, o.Group, o.Deposit)
+	cs, err := findControlled(e, p, "retool", o.CorS)
+	if err != nil {
+		return err
+	}
+	var mg *MineGroup
+	for _, group := range cs.MineGroups {
+		if o.Group == fmt.Sprintf("MG%d", group.No) {
+			mg = group
+			break
+		}
+	}
+	if mg == nil {
+		p.Log("         %s: no such mine group %q\n", o.CorS, o.Group)
+		return fmt.Errorf("no such mine group %q", o.Group)
+	}
+
+	var deposit *Deposit
+	for _, d := range cs.Planet.Deposits {
+		if o.Deposit == fmt.Sprintf("DP%d", d.No) {
+			deposit = d
+			break
+		}
+	}
+	if deposit == nil {
+		p.Log("         %s: no such deposit %q\n", o.CorS, o.Deposit)
+		return fmt.Errorf("no such deposit %q", o.Deposit)
+	} else if deposit.ControlledBy != nil && deposit.ControlledBy.Id != cs.Id {
+		p.Log("         %s: deposit %s: not controlled by you\n", o.CorS, o.Deposit)
+		return fmt.Errorf("invalid deposit %q", o.Deposit)
+	} else if deposit.Id == mg.Deposit.Id {
+		p.Log("         %s: %s: already mining %s\n", o.CorS, o.Group, o.Deposit)
+		return nil
+	}
+	for _, group := range cs.MineGroups {
+		if group.Deposit.Id == deposit.Id {
+			p.Log("         %s: group %2d is already mining %s\n", o.CorS, group.No, o.Deposit)
+			return fmt.Errorf("%q: already mined by group %d", o.Deposit, group.No)
+		}
+	}
+
+	// allocate labor. 1 CON per 500 tonnes of mine units.
+	consRequested := int(math.Ceil(float64(mg.Unit.ActiveQty) * mg.Unit.Unit.MassPerUnit / retoolingTonnesPerCrew))
+	p.Log("         %s: %-20s  %12d requested  %13d available\n", cs.HullId, "construction-crew", consRequested, availableCon(cs))
+	if availableCon(cs) < consRequested {
+		p.Log("         %s: not enough CON to retool %s\n", o.CorS, o.Group)
+		return fmt.Errorf("%s: not enough cons available", o.CorS)
+	}
+	cs.cons.allocated += consRequested
+
+	// salvage the finished ore and scrap the rest of the pipeline
+	stow(cs, mg.Deposit.Product, mg.StageQty[3])
+	p.Log("         %s: %s: salvaged %d %s: scrapped %d in progress\n", o.CorS, o.Group, mg.StageQty[3], mg.Deposit.Product.Name, mg.StageQty[0]+mg.StageQty[1]+mg.StageQty[2])
+
+	// release the old deposit and claim the new one
+	mg.Deposit.ControlledBy = nil
+	deposit.ControlledBy = cs
+	mg.Deposit, mg.StageQty, mg.IdleTurns = deposit, [4]int{}, 1
+
+	p.Log("         %s: %s: retooled to mine %s\n", o.CorS, o.Group, o.Deposit)
+	return nil
+}
+
+// stow adds units to the stowed inventory of a colony or ship.
+// They will not be available until after the bookkeeping phase.
+func stow(cs *CorS, unit *Unit, qty int) {
+	if qty <= 0 {
+		return
+	}
+	for _, u := range cs.Inventory {
+		if u.Unit.Id == unit.Id {
+			u.stowed.create(qty)
+			return
+		}
+	}
+	u := &InventoryUnit{Unit: unit}
+	u.stowed.create(qty)
+	cs.Inventory = append(cs.Inventory, u)
+	sort.Sort(cs.Inventory)
+}
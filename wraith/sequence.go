@@ -0,0 +1,84 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// Orders run phase by phase. Inside a phase, the players take turns and each
+// player's orders run in the sequence they were given to the engine. When two
+// players compete for the same thing, like the last of a deposit or a spot on
+// the market, the player that goes first has the advantage, so the sequence
+// has to be fair.
+//
+// The players are put in a rotation, sorted by id. Each turn the rotation starts
+// with a player picked by a roll seeded from the game and turn. Over many turns
+// every player goes first about as often as any other, but re-running a turn
+// always gives the same sequence.
+//
+// Each player controls the sequence of their own orders by giving them a
+// priority (see orders.Sequence). The sequence is logged so that the results
+// of a turn can be reproduced and explained.
+//
+// Phases that work on every colony and ship, like production, take them in
+// order of id (see sortedCorS) rather than in the random order of the map.
+
+// sequencePlayers returns the players' orders in the sequence they run in this turn.
+func (e *Engine) sequencePlayers(pos []*PhaseOrders) []*PhaseOrders {
+	if len(pos) == 0 {
+		return pos
+	}
+	list := make([]*PhaseOrders, len(pos))
+	copy(list, pos)
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Player.Id < list[j].Player.Id
+	})
+
+	seed := int64(e.Game.Id)<<40 ^ int64(e.Game.Turn.Year*4+e.Game.Turn.Quarter)<<20
+	start := rand.New(rand.NewSource(seed)).Intn(len(list))
+	list = append(list[start:], list[:start]...)
+
+	var ids []string
+	for _, po := range list {
+		ids = append(ids, fmt.Sprintf("%d", po.Player.Id))
+	}
+	log.Printf("execute: turn %04d/%d: seed %d: player sequence %s\n", e.Game.Turn.Year, e.Game.Turn.Quarter, seed, strings.Join(ids, ", "))
+	for n, po := range list {
+		po.Player.Log("\nSequence: player %d goes %d of %d this turn (%s)\n", po.Player.Id, n+1, len(list), strings.Join(ids, ", "))
+	}
+
+	return list
+}
+
+// sortedCorS returns the colonies and ships sorted by id.
+func (e *Engine) sortedCorS() []*CorS {
+	list := make([]*CorS, 0, len(e.CorSById))
+	for _, cs := range e.CorSById {
+		list = append(list, cs)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Id < list[j].Id
+	})
+	return list
+}
@@ -0,0 +1,187 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+import (
+	"fmt"
+	"math"
+)
+
+// stowingTonnesPerCrew is the mass of units that a single construction
+// crew can set up or disassemble in a turn.
+const stowingTonnesPerCrew = 500
+
+// ExecuteSetUpPhase runs all the orders in the setup phase.
+func (e *Engine) ExecuteSetUpPhase(pos []*PhaseOrders) (errs []error) {
+	for _, o := range pos {
+		o.Player.Log("\n\nSet Up ----------------------------------------------------------\n")
+		for _, order := range o.SetUp {
+			if o.skip(e, order) {
+				continue
+			}
+			if err := order.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// ExecuteDisassemblyPhase runs all the orders in the disassembly phase.
+func (e *Engine) ExecuteDisassemblyPhase(pos []*PhaseOrders) (errs []error) {
+	for _, o := range pos {
+		o.Player.Log("\n\nDisassembly -----------------------------------------------------\n")
+		for _, order := range o.Disassembly {
+			if o.skip(e, order) {
+				continue
+			}
+			if err := order.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// Execute converts stowed units into operational units.
+// Operational units take more room than stowed units, so the
+// request is limited by the cargo space available.
+// The units will not be operational until after the bookkeeping phase.
+// Will fail if the colony or ship is not controlled by the player.
+func (o *SetUpOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	p.Log("  setup %s: %13d %s\n", o.CorS, o.Quantity, o.Unit)
+	if o.Quantity <= 0 {
+		p.Log("        %s: nothing to do\n", o.CorS)
+		return nil
+	}
+	cs, u, err := findStowable(e, p, "setup", o.CorS, o.Unit)
+	if err != nil {
+		return err
+	}
+
+	qty := o.Quantity
+	if u.stowed.available() < qty {
+		qty = u.stowed.available()
+	}
+	p.Log("        %s: %-20s  %12d requested  %13d available\n", cs.HullId, u.Unit.Name, o.Quantity, u.stowed.available())
+
+	// setting up units needs room for them to operate in
+	if extra := u.Unit.VolumePerUnit - u.Unit.StowedVolumePerUnit; extra > 0 {
+		if limit := int(float64(cargoCapacity(cs)) / extra); limit < qty {
+			qty = limit
+		}
+		p.Log("        %s: %-20s  %12d requested  %13d available\n", cs.HullId, "cargo volume", int(math.Ceil(float64(qty)*extra)), cargoCapacity(cs))
+	}
+
+	qty = allocateStowingCrews(cs, p, u.Unit, qty)
+	if qty <= 0 {
+		p.Log("        %s: unable to set up %q\n", o.CorS, o.Unit)
+		return fmt.Errorf("%s: unable to set up %q", o.CorS, o.Unit)
+	}
+
+	u.stowed.remove(qty)
+	u.operational.create(qty)
+	p.Log("        %s: set up %d %s\n", o.CorS, qty, u.Unit.Name)
+
+	return nil
+}
+
+// Execute converts operational units into stowed units, usually for shipment.
+// Only units that are flagged as Hudnut can be disassembled.
+// The units will not be stowed until after the bookkeeping phase.
+// Will fail if the colony or ship is not controlled by the player.
+func (o *DisassembleOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	p.Log("  disassemble %s: %13d %s\n", o.CorS, o.Quantity, o.Unit)
+	if o.Quantity <= 0 {
+		p.Log("              %s: nothing to do\n", o.CorS)
+		return nil
+	}
+	cs, u, err := findStowable(e, p, "disassemble", o.CorS, o.Unit)
+	if err != nil {
+		return err
+	}
+
+	qty := o.Quantity
+	if u.operational.available() < qty {
+		qty = u.operational.available()
+	}
+	p.Log("              %s: %-20s  %12d requested  %13d available\n", cs.HullId, u.Unit.Name, o.Quantity, u.operational.available())
+
+	qty = allocateStowingCrews(cs, p, u.Unit, qty)
+	if qty <= 0 {
+		p.Log("              %s: unable to disassemble %q\n", o.CorS, o.Unit)
+		return fmt.Errorf("%s: unable to disassemble %q", o.CorS, o.Unit)
+	}
+
+	u.operational.remove(qty)
+	u.stowed.create(qty)
+	p.Log("              %s: disassembled %d %s\n", o.CorS, qty, u.Unit.Name)
+
+	return nil
+}
+
+// allocateStowingCrews allocates the construction crews needed to set up or
+// disassemble units. 1 CON per 500 tonnes.
+// it returns the number of units that the crews can work on, which will be
+// less than the requested amount when there aren't enough crews available.
+func allocateStowingCrews(cs *CorS, p *Player, unit *Unit, qty int) int {
+	if qty <= 0 {
+		return 0
+	}
+	consRequested := int(math.Ceil(float64(qty) * unit.MassPerUnit / stowingTonnesPerCrew))
+	p.Log("        %s: %-20s  %12d requested  %13d available\n", cs.HullId, "construction-crew", consRequested, availableCon(cs))
+	if availableCon(cs) < consRequested {
+		consRequested = availableCon(cs)
+		if qty = int(math.Floor(float64(consRequested) * stowingTonnesPerCrew / unit.MassPerUnit)); qty <= 0 {
+			return 0
+		}
+	}
+	cs.cons.allocated += consRequested
+	return qty
+}
+
+// findStowable returns the colony or ship and the inventory for a unit that can be set up or disassembled.
+// Will fail if the colony or ship is not controlled by the player or the unit can't be disassembled.
+func findStowable(e *Engine, p *Player, verb, id, unit string) (*CorS, *InventoryUnit, error) {
+	cs, err := findControlled(e, p, verb, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	u, ok := unitFromString(e, unit)
+	if !ok {
+		p.Log("  %s %s: no such unit %q\n", verb, id, unit)
+		return nil, nil, fmt.Errorf("no such unit %q", unit)
+	} else if !u.Hudnut {
+		p.Log("  %s %s: unit %q can not be disassembled\n", verb, id, unit)
+		return nil, nil, fmt.Errorf("%q: can not be disassembled", unit)
+	}
+	for _, inventory := range cs.Inventory {
+		if inventory.Unit.Id == u.Id {
+			return cs, inventory, nil
+		}
+	}
+	p.Log("  %s %s: %q: not in inventory\n", verb, id, unit)
+	return nil, nil, fmt.Errorf("%q: not in inventory", unit)
+}
@@ -0,0 +1,166 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+import (
+	"fmt"
+	"sort"
+)
+
+// sensorsPerOrbit is the number of tech level one sensors needed
+// to survey a single orbit in a turn.
+const sensorsPerOrbit = 10
+
+// ExecuteSurveyPhase runs all the orders in the survey phase.
+// Colonies always know the planet they are on, so every colony
+// updates its nation's survey of its own planet before any orders run.
+func (e *Engine) ExecuteSurveyPhase(pos []*PhaseOrders) (errs []error) {
+	for _, cs := range e.sortedCorS() {
+		if cs.Kind == "ship" || cs.Planet == nil || cs.ControlledBy == nil || cs.ControlledBy.MemberOf == nil {
+			continue
+		}
+		recordSurvey(e, cs.ControlledBy.MemberOf, cs.Planet)
+	}
+
+	for _, o := range pos {
+		o.Player.Log("\n\nSurvey ----------------------------------------------------------\n")
+		for _, order := range o.Survey {
+			if o.skip(e, order) {
+				continue
+			}
+			if err := order.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// SeedHomeSurveys records a survey of each nation's home planet
+// if the nation does not already have one.
+// Nations always know their home planet, so games that were set up
+// before surveys were tracked still report it before any survey phase runs.
+func (e *Engine) SeedHomeSurveys() {
+	for _, n := range e.Nations {
+		if n.HomePlanet == nil {
+			continue
+		} else if _, ok := n.Surveys[n.HomePlanet.Id]; ok {
+			continue
+		}
+		recordSurvey(e, n, n.HomePlanet)
+	}
+}
+
+// Execute surveys the orbits in the target system using the sensors in the hull.
+// If no star is given, every orbit of every star in the system is surveyed.
+// If no orbit is given, every orbit of the star is surveyed.
+// The ship or colony must be in the target system and the number of orbits
+// surveyed is limited by the sensors available.
+// Will fail if the colony or ship is not controlled by the player.
+func (o *SurveyOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	target := o.Coords.String() + o.Star
+	if o.OrbitNo != 0 {
+		target = fmt.Sprintf("%s#%d", target, o.OrbitNo)
+	}
+	p.Log("  survey %s: %s\n", o.CorS, target)
+	cs, err := findControlled(e, p, "survey", o.CorS)
+	if err != nil {
+		return err
+	}
+	if p.MemberOf == nil {
+		p.Log("         %s: player is not a member of a nation\n", o.CorS)
+		return fmt.Errorf("%s: player is not a member of a nation", o.CorS)
+	}
+	if cs.Planet == nil || cs.Planet.System.Coords != o.Coords {
+		p.Log("         %s: not in system %s\n", o.CorS, o.Coords.String())
+		return fmt.Errorf("%s: not in system %s", o.CorS, o.Coords.String())
+	}
+
+	var planets []*Planet
+	for _, star := range cs.Planet.System.Stars {
+		if o.Star != "" && star.Sequence != o.Star {
+			continue
+		}
+		for _, planet := range star.Planets {
+			if planet == nil || (o.OrbitNo != 0 && planet.OrbitNo != o.OrbitNo) {
+				continue
+			}
+			planets = append(planets, planet)
+		}
+	}
+	if len(planets) == 0 {
+		p.Log("         %s: no orbits match %s\n", o.CorS, target)
+		return fmt.Errorf("%s: no orbits match %s", o.CorS, target)
+	}
+
+	capacity := surveyCapacity(cs)
+	p.Log("         %s: %-20s  %12d requested  %13d available\n", cs.HullId, "orbits", len(planets), capacity-cs.surveyed)
+	for _, planet := range planets {
+		if cs.surveyed >= capacity {
+			p.Log("         %s: not enough sensors to survey %s\n", o.CorS, planet.String())
+			continue
+		}
+		cs.surveyed++
+		if d := discoverDeposit(e, planet); d != nil {
+			p.Log("         %s: discovered deposit %d (%s) on %s\n", o.CorS, d.No, d.Product.Code, planet.String())
+		}
+		recordSurvey(e, p.MemberOf, planet)
+		p.Log("         %s: surveyed %s\n", o.CorS, planet.String())
+	}
+
+	return nil
+}
+
+// surveyCapacity returns the number of orbits that the sensors in the hull can survey in a turn.
+func surveyCapacity(cs *CorS) int {
+	sensors := 0
+	for _, u := range cs.Hull {
+		if u.Unit.Kind == "sensor" {
+			sensors += u.ActiveQty * u.Unit.TechLevel
+		}
+	}
+	return sensors / sensorsPerOrbit
+}
+
+// recordSurvey replaces the nation's survey of the planet with the current state of the planet.
+func recordSurvey(e *Engine, n *Nation, planet *Planet) {
+	if n.Surveys == nil {
+		n.Surveys = make(map[int]*Survey)
+	}
+	survey := &Survey{
+		Planet:         planet,
+		Year:           e.Game.Turn.Year,
+		Quarter:        e.Game.Turn.Quarter,
+		Kind:           planet.Kind,
+		HabitabilityNo: planet.HabitabilityNo,
+	}
+	sort.Sort(planet.Deposits)
+	for _, d := range planet.Deposits {
+		survey.Deposits = append(survey.Deposits, &SurveyDeposit{
+			No:           d.No,
+			Product:      d.Product,
+			YieldPct:     depositYield(d),
+			RemainingQty: d.RemainingQty,
+		})
+	}
+	n.Surveys[planet.Id] = survey
+}
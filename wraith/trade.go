@@ -0,0 +1,373 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+import (
+	"fmt"
+	"sort"
+)
+
+// tradingFloor holds the orders posted during the trade phase until they are settled.
+type tradingFloor struct {
+	gold   *Unit
+	bids   []*marketOrder
+	asks   []*marketOrder
+	offers []*tradeOffer
+}
+
+// marketOrder is a bid to buy or an ask to sell units on the market.
+type marketOrder struct {
+	cs    *CorS
+	unit  *Unit
+	qty   int
+	price int // in gold per unit
+}
+
+// tradeOffer is one side of a direct trade between two ships or colonies.
+type tradeOffer struct {
+	cs, partner *CorS
+	unit        *Unit
+	qty         int
+	want        *Unit
+	wantQty     int
+	settled     bool
+}
+
+// ExecuteTradePhase runs all the orders in the trade phase.
+// Orders are posted first, then direct trades are settled, then the market is cleared.
+func (e *Engine) ExecuteTradePhase(pos []*PhaseOrders) (errs []error) {
+	e.floor = &tradingFloor{}
+	for _, unit := range e.Units {
+		if unit.Kind == "gold" {
+			e.floor.gold = unit
+			break
+		}
+	}
+	if e.floor.gold == nil {
+		e.floor = nil
+		return append(errs, fmt.Errorf("trade: no gold unit defined"))
+	}
+
+	for _, o := range pos {
+		o.Player.Log("\n\nTrade -----------------------------------------------------------\n")
+		for _, order := range o.Trade {
+			if o.skip(e, order) {
+				continue
+			}
+			if err := order.Buy.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+			if err := order.Offer.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+			if err := order.Sell.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	errs = append(errs, e.settleOffers()...)
+	e.Market = e.clearMarket()
+	e.floor = nil
+
+	return errs
+}
+
+// Execute posts a bid to buy units on the market.
+// The order is not filled until the market clears.
+// Will fail if the colony or ship is not controlled by the player.
+func (o *BuyOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	p.Log("  buy %s: %13d %s at %d gold\n", o.CorS, o.Quantity, o.Unit, o.Price)
+	if o.Quantity <= 0 {
+		p.Log("      %s: nothing to do\n", o.CorS)
+		return nil
+	}
+	cs, err := findControlled(e, p, "buy", o.CorS)
+	if err != nil {
+		return err
+	}
+	unit, ok := unitFromString(e, o.Unit)
+	if !ok {
+		p.Log("      %s: no such unit %q\n", o.CorS, o.Unit)
+		return fmt.Errorf("no such unit %q", o.Unit)
+	} else if unit == e.floor.gold {
+		p.Log("      %s: can not buy gold with gold\n", o.CorS)
+		return fmt.Errorf("%s: can not buy gold", o.CorS)
+	} else if o.Price <= 0 {
+		p.Log("      %s: invalid price %d\n", o.CorS, o.Price)
+		return fmt.Errorf("%s: invalid price %d", o.CorS, o.Price)
+	}
+	e.floor.bids = append(e.floor.bids, &marketOrder{cs: cs, unit: unit, qty: o.Quantity, price: o.Price})
+	p.Log("      %s: posted bid for %d %s\n", o.CorS, o.Quantity, unit.Code)
+	return nil
+}
+
+// Execute posts an ask to sell units on the market.
+// The order is not filled until the market clears.
+// Will fail if the colony or ship is not controlled by the player or doesn't have the units.
+func (o *SellOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	p.Log("  sell %s: %13d %s at %d gold\n", o.CorS, o.Quantity, o.Unit, o.Price)
+	if o.Quantity <= 0 {
+		p.Log("       %s: nothing to do\n", o.CorS)
+		return nil
+	}
+	cs, err := findControlled(e, p, "sell", o.CorS)
+	if err != nil {
+		return err
+	}
+	unit, ok := unitFromString(e, o.Unit)
+	if !ok {
+		p.Log("       %s: no such unit %q\n", o.CorS, o.Unit)
+		return fmt.Errorf("no such unit %q", o.Unit)
+	} else if unit == e.floor.gold {
+		p.Log("       %s: can not sell gold for gold\n", o.CorS)
+		return fmt.Errorf("%s: can not sell gold", o.CorS)
+	} else if o.Price <= 0 {
+		p.Log("       %s: invalid price %d\n", o.CorS, o.Price)
+		return fmt.Errorf("%s: invalid price %d", o.CorS, o.Price)
+	} else if availableUnits(cs, unit) == 0 {
+		p.Log("       %s: %q: not in inventory\n", o.CorS, o.Unit)
+		return fmt.Errorf("%q: not in inventory", o.Unit)
+	}
+	e.floor.asks = append(e.floor.asks, &marketOrder{cs: cs, unit: unit, qty: o.Quantity, price: o.Price})
+	p.Log("       %s: posted ask for %d %s\n", o.CorS, o.Quantity, unit.Code)
+	return nil
+}
+
+// Execute posts one side of a direct trade with a ship or colony on the same planet.
+// The trade only happens if the partner posts the matching offer this turn.
+// Will fail if the colony or ship is not controlled by the player or the partner is not on the same planet.
+func (o *OfferOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	p.Log("  offer %s: %13d %s to %s for %d %s\n", o.CorS, o.Quantity, o.Unit, o.Partner, o.WantQuantity, o.WantUnit)
+	if o.Quantity <= 0 || o.WantQuantity <= 0 {
+		p.Log("        %s: nothing to do\n", o.CorS)
+		return nil
+	}
+	cs, err := findControlled(e, p, "offer", o.CorS)
+	if err != nil {
+		return err
+	}
+	partner, ok := e.findColony(o.Partner)
+	if !ok {
+		partner, ok = e.findShip(o.Partner)
+	}
+	if !ok || partner.Planet != cs.Planet {
+		p.Log("        %s: no such partner %s\n", o.CorS, o.Partner)
+		return fmt.Errorf("no such partner %q", o.Partner)
+	} else if partner == cs {
+		p.Log("        %s: can not trade with self\n", o.CorS)
+		return fmt.Errorf("%s: can not trade with self", o.CorS)
+	}
+	unit, ok := unitFromString(e, o.Unit)
+	if !ok {
+		p.Log("        %s: no such unit %q\n", o.CorS, o.Unit)
+		return fmt.Errorf("no such unit %q", o.Unit)
+	}
+	want, ok := unitFromString(e, o.WantUnit)
+	if !ok {
+		p.Log("        %s: no such unit %q\n", o.CorS, o.WantUnit)
+		return fmt.Errorf("no such unit %q", o.WantUnit)
+	}
+	e.floor.offers = append(e.floor.offers, &tradeOffer{cs: cs, partner: partner, unit: unit, qty: o.Quantity, want: want, wantQty: o.WantQuantity})
+	p.Log("        %s: posted offer to %s\n", o.CorS, o.Partner)
+	return nil
+}
+
+// settleOffers completes the direct trades where both sides posted matching offers.
+// Trades are all or nothing; if either side can't deliver or doesn't have room, nothing changes hands.
+func (e *Engine) settleOffers() (errs []error) {
+	for i, a := range e.floor.offers {
+		if a.settled {
+			continue
+		}
+		var b *tradeOffer
+		for _, o := range e.floor.offers[i+1:] {
+			if !o.settled && o.cs == a.partner && o.partner == a.cs && o.unit == a.want && o.qty == a.wantQty && o.want == a.unit && o.wantQty == a.qty {
+				b = o
+				break
+			}
+		}
+		if b == nil {
+			a.cs.Log("  offer %s: no matching offer from %s\n", a.cs.HullId, a.partner.HullId)
+			continue
+		}
+		a.settled, b.settled = true, true
+
+		var err error
+		if availableUnits(a.cs, a.unit) < a.qty {
+			err = fmt.Errorf("%s: not enough %s to trade", a.cs.HullId, a.unit.Code)
+		} else if availableUnits(b.cs, b.unit) < b.qty {
+			err = fmt.Errorf("%s: not enough %s to trade", b.cs.HullId, b.unit.Code)
+		} else if cargoVolume(a.unit, a.qty, 0) > cargoCapacity(b.cs) {
+			err = fmt.Errorf("%s: no room for %s", b.cs.HullId, a.unit.Code)
+		} else if cargoVolume(b.unit, b.qty, 0) > cargoCapacity(a.cs) {
+			err = fmt.Errorf("%s: no room for %s", a.cs.HullId, b.unit.Code)
+		}
+		if err != nil {
+			a.cs.Log("  offer %s: trade with %s failed: %v\n", a.cs.HullId, b.cs.HullId, err)
+			b.cs.Log("  offer %s: trade with %s failed: %v\n", b.cs.HullId, a.cs.HullId, err)
+			errs = append(errs, err)
+			continue
+		}
+
+		receiveUnits(b.cs, a.unit, removeUnits(a.cs, a.unit, a.qty))
+		receiveUnits(a.cs, b.unit, removeUnits(b.cs, b.unit, b.qty))
+		a.cs.Log("  offer %s: traded %d %s to %s for %d %s\n", a.cs.HullId, a.qty, a.unit.Code, b.cs.HullId, b.qty, b.unit.Code)
+		b.cs.Log("  offer %s: traded %d %s to %s for %d %s\n", b.cs.HullId, b.qty, b.unit.Code, a.cs.HullId, a.qty, a.unit.Code)
+	}
+	return errs
+}
+
+// clearMarket matches the highest bids with the lowest asks for each unit.
+// Each match trades at the midpoint of the bid and the ask.
+// Fills are limited by the seller's inventory and the buyer's gold and cargo space.
+// Returns the activity for every unit that had a bid or an ask.
+func (e *Engine) clearMarket() (reports MarketReports) {
+	gold := e.floor.gold
+	bids, asks := make(map[*Unit][]*marketOrder), make(map[*Unit][]*marketOrder)
+	var units []*Unit
+	for _, o := range e.floor.bids {
+		if bids[o.unit] == nil {
+			units = append(units, o.unit)
+		}
+		bids[o.unit] = append(bids[o.unit], o)
+	}
+	for _, o := range e.floor.asks {
+		if bids[o.unit] == nil && asks[o.unit] == nil {
+			units = append(units, o.unit)
+		}
+		asks[o.unit] = append(asks[o.unit], o)
+	}
+	sort.Slice(units, func(i, j int) bool {
+		return units[i].Id < units[j].Id
+	})
+
+	for _, unit := range units {
+		report := &MarketReport{Unit: unit}
+		reports = append(reports, report)
+
+		buyers, sellers := bids[unit], asks[unit]
+		sort.SliceStable(buyers, func(i, j int) bool {
+			return buyers[i].price > buyers[j].price
+		})
+		sort.SliceStable(sellers, func(i, j int) bool {
+			return sellers[i].price < sellers[j].price
+		})
+		for _, o := range buyers {
+			report.BidQty += o.qty
+		}
+		for _, o := range sellers {
+			report.AskQty += o.qty
+		}
+
+		value := 0
+		for i, j := 0, 0; i < len(buyers) && j < len(sellers) && buyers[i].price >= sellers[j].price; {
+			buyer, seller := buyers[i], sellers[j]
+			price := (buyer.price + seller.price) / 2
+
+			qty := buyer.qty
+			if seller.qty < qty {
+				qty = seller.qty
+			}
+			if available := availableUnits(seller.cs, unit); available < qty {
+				qty = available
+			}
+			if qty == 0 {
+				seller.cs.Log("  sell %s: no %s left to sell\n", seller.cs.HullId, unit.Code)
+				j++
+				continue
+			}
+			if affordable := availableUnits(buyer.cs, gold) / price; affordable < qty {
+				qty = affordable
+			}
+			if unit.StowedVolumePerUnit > 0 {
+				if room := int(float64(cargoCapacity(buyer.cs)) / unit.StowedVolumePerUnit); room < qty {
+					qty = room
+				}
+			}
+			if qty <= 0 {
+				buyer.cs.Log("  buy %s: not enough gold or cargo space to buy %s\n", buyer.cs.HullId, unit.Code)
+				i++
+				continue
+			}
+
+			receiveUnits(buyer.cs, unit, removeUnits(seller.cs, unit, qty))
+			receiveUnits(seller.cs, gold, removeUnits(buyer.cs, gold, qty*price))
+			buyer.cs.Log("  buy %s: bought %d %s at %d gold\n", buyer.cs.HullId, qty, unit.Code, price)
+			seller.cs.Log("  sell %s: sold %d %s at %d gold\n", seller.cs.HullId, qty, unit.Code, price)
+
+			if report.Volume == 0 || price < report.LowPrice {
+				report.LowPrice = price
+			}
+			if price > report.HighPrice {
+				report.HighPrice = price
+			}
+			report.Volume, value = report.Volume+qty, value+qty*price
+
+			if buyer.qty -= qty; buyer.qty == 0 {
+				i++
+			}
+			if seller.qty -= qty; seller.qty == 0 {
+				j++
+			}
+		}
+		if report.Volume != 0 {
+			report.AvgPrice = float64(value) / float64(report.Volume)
+		}
+	}
+
+	return reports
+}
+
+// availableUnits returns the number of stowed and operational units in inventory that haven't been used this turn.
+func availableUnits(cs *CorS, unit *Unit) int {
+	for _, u := range cs.Inventory {
+		if u.Unit.Id == unit.Id {
+			return u.stowed.available() + u.operational.available()
+		}
+	}
+	return 0
+}
+
+// removeUnits takes units out of inventory, stowed units first.
+// It returns the number of units removed.
+func removeUnits(cs *CorS, unit *Unit, qty int) (removed int) {
+	for _, u := range cs.Inventory {
+		if u.Unit.Id == unit.Id {
+			removed = u.stowed.remove(qty)
+			removed += u.operational.remove(qty - removed)
+			break
+		}
+	}
+	return removed
+}
+
+// receiveUnits adds units to inventory.
+// The units arrive stowed and will not be available until after the bookkeeping phase.
+func receiveUnits(cs *CorS, unit *Unit, qty int) {
+	stow(cs, unit, qty)
+}
@@ -0,0 +1,248 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ExecuteTransferPhase runs all the orders in the transfer phase.
+func (e *Engine) ExecuteTransferPhase(pos []*PhaseOrders) (errs []error) {
+	for _, o := range pos {
+		o.Player.Log("\n\nTransfer --------------------------------------------------------\n")
+		for _, order := range o.Transfer {
+			if o.skip(e, order) {
+				continue
+			}
+			if err := order.Population.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+			if err := order.Unit.Execute(e, o.Player); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// Execute moves population between a colony or ship and another at the same location.
+// The people moved will not be available at the destination until after bookkeeping.
+// Will fail if the source is not controlled by the player or the destination is not in the same orbit.
+func (o *TransferPopulationOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	p.Log("  transfer %s: %s %d %s\n", o.From, o.To, o.Quantity, o.Class)
+	if o.Quantity <= 0 {
+		p.Log("           %s: nothing to do\n", o.From)
+		return nil
+	}
+	from, to, err := findTransferEndpoints(e, p, o.From, o.To)
+	if err != nil {
+		return err
+	}
+
+	var src, dst *requisition
+	switch o.Class {
+	case "professional":
+		src, dst = &from.pro, &to.pro
+	case "soldier":
+		src, dst = &from.sol, &to.sol
+	case "unskilled":
+		src, dst = &from.uns, &to.uns
+	case "unemployed":
+		src, dst = &from.uem, &to.uem
+	case "construction-crew":
+		src, dst = &from.cons, &to.cons
+	case "spy-team":
+		src, dst = &from.spy, &to.spy
+	default:
+		p.Log("           %s: no such population class %q\n", o.From, o.Class)
+		return fmt.Errorf("no such population class %q", o.Class)
+	}
+
+	qty := o.Quantity
+	if available := src.operational - src.allocated; available < qty {
+		p.Log("           %s: not enough %s to transfer %d\n", o.From, o.Class, o.Quantity)
+		if qty = available; qty <= 0 {
+			return fmt.Errorf("%s: no %s available", o.From, o.Class)
+		}
+		p.Log("                 reducing request to %d\n", qty)
+	}
+	src.operational -= qty
+	dst.create(qty)
+
+	p.Log("           %s: transferred %d %s to %s\n", o.From, qty, o.Class, o.To)
+
+	return nil
+}
+
+// Execute moves units between a colony or ship and another at the same location.
+// Stowed units are moved before operational units.
+// Will fail if the source is not controlled by the player, the destination is not in the same orbit,
+// or the destination does not have enough room for the cargo.
+func (o *TransferUnitOrder) Execute(e *Engine, p *Player) error {
+	if o == nil {
+		return nil
+	}
+	p.Log("  transfer %s: %s %d %s\n", o.From, o.To, o.Quantity, o.Unit)
+	if o.Quantity <= 0 {
+		p.Log("           %s: nothing to do\n", o.From)
+		return nil
+	}
+	from, to, err := findTransferEndpoints(e, p, o.From, o.To)
+	if err != nil {
+		return err
+	}
+
+	unit, ok := unitFromString(e, o.Unit)
+	if !ok {
+		p.Log("           %s: no such unit %q\n", o.From, o.Unit)
+		return fmt.Errorf("no such unit %q", o.Unit)
+	}
+	var src *InventoryUnit
+	for _, u := range from.Inventory {
+		if u.Unit.Id == unit.Id {
+			src = u
+			break
+		}
+	}
+	if src == nil {
+		p.Log("           %s: %q: not in inventory\n", o.From, o.Unit)
+		return fmt.Errorf("%q: not in inventory", o.Unit)
+	}
+
+	// be optimistic and assume that we'll transfer everything requested
+	stowed, active := o.Quantity, 0
+	if available := src.stowed.available(); available < stowed {
+		stowed, active = available, stowed-available
+		if available = src.operational.available(); available < active {
+			active = available
+		}
+	}
+	if stowed+active < o.Quantity {
+		p.Log("           %s: not enough inventory to transfer %d %q\n", o.From, o.Quantity, o.Unit)
+		p.Log("                 reducing request to %d\n", stowed+active)
+		if stowed+active == 0 {
+			return fmt.Errorf("%q: not available", o.Unit)
+		}
+	}
+
+	// verify that the destination has room for the cargo
+	capacity := cargoCapacity(to)
+	if volume := cargoVolume(unit, stowed, active); volume > capacity {
+		p.Log("           %s: not enough room to transfer %d %q\n", o.To, stowed+active, o.Unit)
+		if !isZero(unit.StowedVolumePerUnit) && capacity < cargoVolume(unit, stowed, 0) {
+			stowed = int(float64(capacity) / unit.StowedVolumePerUnit)
+		}
+		capacity -= cargoVolume(unit, stowed, 0)
+		if !isZero(unit.VolumePerUnit) && capacity < cargoVolume(unit, 0, active) {
+			if active = int(float64(capacity) / unit.VolumePerUnit); active < 0 {
+				active = 0
+			}
+		}
+		p.Log("                 reducing request to %d\n", stowed+active)
+		if stowed+active == 0 {
+			return fmt.Errorf("%s: no room for %q", o.To, o.Unit)
+		}
+	}
+
+	var dst *InventoryUnit
+	for _, u := range to.Inventory {
+		if u.Unit.Id == unit.Id {
+			dst = u
+			break
+		}
+	}
+	if dst == nil {
+		dst = &InventoryUnit{Unit: unit}
+		to.Inventory = append(to.Inventory, dst)
+		sort.Sort(to.Inventory)
+	}
+	dst.stowed.create(src.stowed.remove(stowed))
+	dst.operational.create(src.operational.remove(active))
+
+	p.Log("           %s: transferred %d %s to %s\n", o.From, stowed+active, unit.Code, o.To)
+
+	return nil
+}
+
+// cargoCapacity returns the volume (in cubic meters) available for cargo.
+// surface colonies are open to the air, so they have no limit.
+// everything else needs structural units to enclose the hull and cargo.
+func cargoCapacity(cs *CorS) int {
+	var factor int
+	switch cs.Kind {
+	case "open", "surface":
+		return math.MaxInt
+	case "enclosed":
+		factor = 5
+	default:
+		factor = 10
+	}
+	enclosed, used := 0, 0
+	for _, u := range cs.Hull {
+		if u.Unit.Code == "STUN" || u.Unit.Code == "LTSU" || u.Unit.Code == "SLSU" {
+			enclosed += u.ActiveQty
+			continue
+		}
+		used += u.totalVolume()
+	}
+	for _, u := range cs.Inventory {
+		used += cargoVolume(u.Unit, u.StowedQty+u.stowed.created-u.stowed.destroyed, u.ActiveQty+u.operational.created-u.operational.destroyed)
+	}
+	return enclosed/factor - used
+}
+
+// cargoVolume returns the volume (in cubic meters) of the stowed and active units.
+func cargoVolume(u *Unit, stowed, active int) int {
+	return int(math.Ceil(float64(stowed)*u.StowedVolumePerUnit)) + int(math.Ceil(float64(active)*u.VolumePerUnit))
+}
+
+// findTransferEndpoints returns the source and destination for a transfer.
+func findTransferEndpoints(e *Engine, p *Player, fromId, toId string) (from, to *CorS, err error) {
+	// find colony or ship
+	from, ok := e.findColony(fromId)
+	if !ok {
+		if from, ok = e.findShip(fromId); !ok {
+			p.Log("           %s: no such colony or ship\n", fromId)
+			return nil, nil, fmt.Errorf("no such colony or ship %q", fromId)
+		}
+	}
+	// fail if controlled by another player
+	if from.ControlledBy != nil && from.ControlledBy != p {
+		p.Log("           %s: no such colony or ship\n", fromId)
+		return nil, nil, fmt.Errorf("no such colony or ship %q", fromId)
+	}
+	// the destination must be in the same orbit
+	to, ok = e.findColony(toId)
+	if !ok {
+		to, ok = e.findShip(toId)
+	}
+	if !ok || to.Planet != from.Planet {
+		p.Log("           %s: no such colony or ship\n", toId)
+		return nil, nil, fmt.Errorf("no such colony or ship %q", toId)
+	} else if to == from {
+		p.Log("           %s: can not transfer to self\n", fromId)
+		return nil, nil, fmt.Errorf("%s: can not transfer to self", fromId)
+	}
+	return from, to, nil
+}
@@ -0,0 +1,152 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	// combatUnrestRate is the increase in the rebel percentage for each percent of the population killed in combat
+	combatUnrestRate = 0.5
+	// conditionsUnrestRate is the change in the rebel percentage for each point the standard
+	// of living is below (or above) the neutral standard of living
+	conditionsUnrestRate = 0.05
+	// neutralStandardOfLiving is the standard of living that neither raises nor lowers unrest
+	neutralStandardOfLiving = 0.75
+	// soldierSuppression is the decrease in the rebel percentage for each percent of the population that are soldiers
+	soldierSuppression = 0.25
+	// unrestThreshold is the rebel percentage where production starts to suffer
+	unrestThreshold = 0.1
+	// revoltThreshold is the rebel percentage where production stops and the rebels may revolt
+	revoltThreshold = 0.5
+	// rebelsPerSoldier is the number of rebels that a single soldier can hold down
+	rebelsPerSoldier = 5
+	// revoltAftermath is the rebel percentage left after a revolt succeeds
+	revoltAftermath = 0.05
+)
+
+// ExecuteUnrestPhase updates the rebel percentage on every colony and ship.
+// Good conditions and soldiers suppress unrest; poor conditions raise it.
+// When the rebels outnumber what the soldiers can hold down, they revolt.
+// A successful revolt hands the colony or ship to the player that incited it
+// this turn or makes it independent.
+func (e *Engine) ExecuteUnrestPhase(pos []*PhaseOrders) (errs []error) {
+	for _, o := range pos {
+		o.Player.Log("\n\nUnrest ----------------------------------------------------------\n")
+	}
+	for _, cs := range e.sortedCorS() {
+		total := cs.Population.Total()
+		if total == 0 {
+			continue
+		}
+		soldierPct := float64(cs.Population.SoldierQty) / float64(total)
+		delta := conditionsUnrestRate*(neutralStandardOfLiving-standardOfLiving(cs)) - soldierSuppression*soldierPct
+		cs.Population.RebelPct = math.Max(0, math.Min(1, cs.Population.RebelPct+delta))
+		cs.Log("  unrest %s: rebels %6.2f%%\n", cs.HullId, cs.Population.RebelPct*100)
+
+		if cs.Population.RebelPct < revoltThreshold {
+			continue
+		}
+		rebels := int(cs.Population.RebelPct * float64(total))
+		if rebels <= cs.Population.SoldierQty*rebelsPerSoldier {
+			cs.Population.RebelPct = cs.Population.RebelPct / 2
+			cs.Log("         %s: revolt crushed: rebels %6.2f%%\n", cs.HullId, cs.Population.RebelPct*100)
+			continue
+		}
+		revolt(cs)
+	}
+	return errs
+}
+
+// revolt hands the colony or ship to the player that incited it or makes it independent.
+// The news goes in the unrest report of the old and new controllers.
+func revolt(cs *CorS) {
+	from, to := cs.ControlledBy, cs.incitedBy
+	if to == from {
+		to = nil
+	}
+	cs.Log("         %s: revolt succeeded\n", cs.HullId)
+	changeControl(cs, to)
+	cs.Population.RebelPct = revoltAftermath
+
+	if to == nil {
+		if from != nil {
+			from.UnrestReport = append(from.UnrestReport, fmt.Sprintf("%s: rebels revolted and declared independence", cs.HullId))
+		}
+		return
+	}
+	to.Log("  unrest %s: rebels revolted and handed control to %s\n", cs.HullId, to.Name)
+	to.UnrestReport = append(to.UnrestReport, fmt.Sprintf("%s: rebels revolted and handed control to you", cs.HullId))
+	if from != nil {
+		from.UnrestReport = append(from.UnrestReport, fmt.Sprintf("%s: rebels revolted and handed control to %s", cs.HullId, to.Name))
+	}
+}
+
+// changeControl hands the colony or ship to a new controller, which may be nil.
+// It is moved from the old controller's list of colonies or ships to the new one's.
+func changeControl(cs *CorS, to *Player) {
+	if from := cs.ControlledBy; from != nil {
+		if cs.Kind == "ship" {
+			from.Ships = removeCorS(from.Ships, cs)
+		} else {
+			from.Colonies = removeCorS(from.Colonies, cs)
+		}
+	}
+	cs.ControlledBy = to
+	if to != nil {
+		if cs.Kind == "ship" {
+			to.Ships = append(to.Ships, cs)
+			sort.Sort(to.Ships)
+		} else {
+			to.Colonies = append(to.Colonies, cs)
+			sort.Sort(to.Colonies)
+		}
+	}
+}
+
+// removeCorS returns the list without the colony or ship.
+func removeCorS(list CorSs, cs *CorS) CorSs {
+	for i, c := range list {
+		if c == cs {
+			return append(list[:i], list[i+1:]...)
+		}
+	}
+	return list
+}
+
+// combatUnrest raises the rebel percentage in proportion to the population killed in combat.
+func combatUnrest(c *combatant) {
+	if c.deaths == 0 {
+		return
+	}
+	pct := float64(c.deaths) / float64(totalPop(c.cs)+c.deaths)
+	c.cs.Population.RebelPct = math.Min(1, c.cs.Population.RebelPct+pct*combatUnrestRate)
+}
+
+// unrestPenalty returns the share of production lost to unrest.
+// Production falls off once unrest passes the threshold and stops when the rebels are ready to revolt.
+func unrestPenalty(cs *CorS) float64 {
+	if cs.Population.RebelPct <= unrestThreshold {
+		return 0
+	}
+	return math.Min(1, (cs.Population.RebelPct-unrestThreshold)/(revoltThreshold-unrestThreshold))
+}
@@ -0,0 +1,93 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+import (
+	"testing"
+)
+
+func TestRevolt(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		kind     string
+		incited  bool   // true if the other player incited the rebels
+		wantFrom string // unrest report for the old controller
+		wantTo   string // unrest report for the player that incited the rebels
+	}{
+		{name: "colony declares independence",
+			kind:     "surface",
+			wantFrom: "C1: rebels revolted and declared independence",
+		},
+		{name: "colony handed to inciter",
+			kind:     "surface",
+			incited:  true,
+			wantFrom: "C1: rebels revolted and handed control to beta",
+			wantTo:   "C1: rebels revolted and handed control to you",
+		},
+		{name: "ship handed to inciter",
+			kind:     "ship",
+			incited:  true,
+			wantFrom: "C1: rebels revolted and handed control to beta",
+			wantTo:   "C1: rebels revolted and handed control to you",
+		},
+	} {
+		from, to := &Player{Id: 1, Name: "alpha"}, &Player{Id: 2, Name: "beta"}
+		other := &CorS{HullId: "C2", MSN: 2, Kind: tc.kind, ControlledBy: from}
+		cs := &CorS{HullId: "C1", MSN: 1, Kind: tc.kind, ControlledBy: from}
+		if tc.kind == "ship" {
+			from.Ships = CorSs{cs, other}
+		} else {
+			from.Colonies = CorSs{cs, other}
+		}
+		if tc.incited {
+			cs.incitedBy = to
+		}
+
+		revolt(cs)
+
+		var controller *Player
+		if tc.incited {
+			controller = to
+		}
+		if cs.ControlledBy != controller {
+			t.Errorf("%s: controlled by: want %v: got %v", tc.name, controller, cs.ControlledBy)
+		}
+		if got := len(from.Colonies) + len(from.Ships); got != 1 {
+			t.Errorf("%s: old controller: want 1 colony or ship: got %d", tc.name, got)
+		}
+		gotTo := append(append(CorSs{}, to.Colonies...), to.Ships...)
+		if tc.incited && (len(gotTo) != 1 || gotTo[0] != cs) {
+			t.Errorf("%s: new controller: want %s: got %d colonies or ships", tc.name, cs.HullId, len(gotTo))
+		} else if !tc.incited && len(gotTo) != 0 {
+			t.Errorf("%s: other player: want no colonies or ships: got %d", tc.name, len(gotTo))
+		}
+		if tc.kind == "ship" && (len(to.Colonies) != 0 || (tc.incited && len(to.Ships) != 1)) {
+			t.Errorf("%s: want ship in list of ships", tc.name)
+		}
+		if len(from.UnrestReport) != 1 || from.UnrestReport[0] != tc.wantFrom {
+			t.Errorf("%s: old controller report: want %q: got %q", tc.name, tc.wantFrom, from.UnrestReport)
+		}
+		if tc.wantTo != "" && (len(to.UnrestReport) != 1 || to.UnrestReport[0] != tc.wantTo) {
+			t.Errorf("%s: new controller report: want %q: got %q", tc.name, tc.wantTo, to.UnrestReport)
+		}
+		if from.CombatReport != nil || to.CombatReport != nil {
+			t.Errorf("%s: want no combat report", tc.name)
+		}
+	}
+}
@@ -0,0 +1,736 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// errors returned by Validate.
+// callers should use errors.Is to classify them.
+var (
+	ErrInfeasible    = errors.New("not feasible")
+	ErrNotCoLocated  = errors.New("not at the same location")
+	ErrNotControlled = errors.New("not controlled by player")
+	ErrNotFound      = errors.New("not found")
+	ErrShortfall     = errors.New("not enough on hand")
+)
+
+// Validate checks the orders against the current state of the game without running them.
+// It looks for colonies, ships, units, deposits, and groups that don't exist or aren't
+// controlled by the player, targets that aren't at the same location, orders the
+// colony or ship can't carry out, and quantities that can't be met from the starting
+// inventory and population.
+// The orders are checked together, in the order the phases run, so quantities
+// used by earlier orders aren't available to later ones.
+// Each error is an *OrderError that holds the order it was found in.
+// Nothing is logged and the state of the game is not changed.
+func (e *Engine) Validate(po *PhaseOrders) (errs []error) {
+	if po == nil {
+		return nil
+	}
+	v := &validator{e: e, p: po.Player, used: make(map[string]int)}
+	for _, o := range po.Combat {
+		if o.Attack != nil {
+			errs = v.append(errs, o, v.attack(o.Attack))
+		}
+		if o.Defend != nil {
+			_, err := v.controlled(o.Defend.CorS)
+			errs = v.append(errs, o, err)
+		}
+		if o.Raid != nil {
+			errs = v.append(errs, o, v.raid(o.Raid))
+		}
+	}
+	for _, o := range po.SetUp {
+		errs = v.append(errs, o, v.stowable(o.CorS, o.Unit, o.Quantity, true))
+	}
+	for _, o := range po.Disassembly {
+		errs = v.append(errs, o, v.stowable(o.CorS, o.Unit, o.Quantity, false))
+	}
+	for _, o := range po.Retool {
+		if o.FactoryGroup != nil {
+			errs = v.append(errs, o, v.retoolFactoryGroup(o.FactoryGroup))
+		}
+		if o.MiningGroup != nil {
+			errs = v.append(errs, o, v.retoolMiningGroup(o.MiningGroup))
+		}
+	}
+	for _, o := range po.Transfer {
+		if o.Population != nil {
+			errs = v.append(errs, o, v.transferPopulation(o.Population))
+		}
+		if o.Unit != nil {
+			errs = v.append(errs, o, v.transferUnit(o.Unit))
+		}
+	}
+	for _, o := range po.Assembly {
+		if o.ConstructionCrew != nil {
+			errs = v.append(errs, o, v.crew(o.ConstructionCrew.CorS, o.ConstructionCrew.Quantity))
+		}
+		if o.FactoryGroup != nil {
+			errs = v.append(errs, o, v.assembleFactoryGroup(o.FactoryGroup))
+		}
+		if o.FarmGroup != nil {
+			errs = v.append(errs, o, v.assembleFarmGroup(o.FarmGroup))
+		}
+		if o.MiningGroup != nil {
+			errs = v.append(errs, o, v.assembleMineGroup(o.MiningGroup))
+		}
+		if o.SpyTeam != nil {
+			errs = v.append(errs, o, v.crew(o.SpyTeam.CorS, o.SpyTeam.Quantity))
+		}
+	}
+	for _, o := range po.Build {
+		if o.Colony != nil {
+			errs = v.append(errs, o, v.buildColony(o.Colony))
+		}
+		if o.Ship != nil {
+			errs = v.append(errs, o, v.buildShip(o.Ship))
+		}
+	}
+	for _, o := range po.Trade {
+		if o.Buy != nil {
+			errs = v.append(errs, o, v.buy(o.Buy))
+		}
+		if o.Offer != nil {
+			errs = v.append(errs, o, v.offer(o.Offer))
+		}
+		if o.Sell != nil {
+			errs = v.append(errs, o, v.sell(o.Sell))
+		}
+	}
+	for _, o := range po.Survey {
+		errs = v.append(errs, o, v.survey(o))
+	}
+	for _, o := range po.Espionage {
+		if o.CounterIntel != nil {
+			errs = v.append(errs, o, v.spies(o.CounterIntel.CorS, o.CounterIntel.Quantity, ""))
+		}
+		if o.GatherIntel != nil {
+			errs = v.append(errs, o, v.spies(o.GatherIntel.CorS, o.GatherIntel.Quantity, o.GatherIntel.Target))
+		}
+		if o.Incite != nil {
+			errs = v.append(errs, o, v.spies(o.Incite.CorS, o.Incite.Quantity, o.Incite.Target))
+		}
+		if o.Sabotage != nil {
+			errs = v.append(errs, o, v.spies(o.Sabotage.CorS, o.Sabotage.Quantity, o.Sabotage.Target))
+		}
+	}
+	for _, o := range po.Movement {
+		if o.Jump != nil {
+			errs = v.append(errs, o, v.jump(o.Jump))
+		}
+		if o.Move != nil {
+			errs = v.append(errs, o, v.move(o.Move))
+		}
+	}
+	for _, o := range po.Draft {
+		errs = v.append(errs, o, v.draft(o))
+	}
+	for _, o := range po.Pay {
+		_, err := v.controlled(o.CorS)
+		errs = v.append(errs, o, err)
+	}
+	for _, o := range po.Ration {
+		_, err := v.controlled(o.CorS)
+		errs = v.append(errs, o, err)
+	}
+	for _, o := range po.Research {
+		errs = v.append(errs, o, v.research(o))
+	}
+	for _, o := range po.Control {
+		if o.ControlColony != nil {
+			errs = v.append(errs, o, v.controlColony(o.ControlColony.Id))
+		}
+		if o.ControlShip != nil {
+			_, err := v.ship(o.ControlShip.Id)
+			errs = v.append(errs, o, err)
+		}
+		if o.NameColony != nil {
+			_, err := v.controlled(o.NameColony.Id)
+			errs = v.append(errs, o, err)
+		}
+		if o.NameShip != nil {
+			_, err := v.controlled(o.NameShip.Id)
+			errs = v.append(errs, o, err)
+		}
+	}
+	for _, o := range po.Maintain {
+		errs = v.append(errs, o, v.maintain(o))
+	}
+	for o, c := range po.Conditions {
+		errs = v.append(errs, o, v.condition(c))
+	}
+	return errs
+}
+
+// OrderError is an error found in a single order.
+// Order is the phase order, the same value used to key PhaseOrders.Conditions.
+type OrderError struct {
+	Order interface{}
+	Err   error
+}
+
+func (e *OrderError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *OrderError) Unwrap() error {
+	return e.Err
+}
+
+// validator holds the state needed to check a player's orders.
+type validator struct {
+	e    *Engine
+	p    *Player
+	used map[string]int // running totals of the quantities used by the orders, keyed by hull id and what was used
+}
+
+// append adds the error for the order to the list if it isn't nil.
+func (v *validator) append(errs []error, order interface{}, err error) []error {
+	if err == nil {
+		return errs
+	}
+	return append(errs, &OrderError{Order: order, Err: err})
+}
+
+// find returns the colony or ship with the given id.
+func (v *validator) find(id string) (*CorS, error) {
+	cs, ok := v.e.findColony(id)
+	if !ok {
+		cs, ok = v.e.findShip(id)
+	}
+	if !ok {
+		return nil, fmt.Errorf("colony or ship %q: %w", id, ErrNotFound)
+	}
+	return cs, nil
+}
+
+// controlled returns the colony or ship if it can be given orders by the player.
+// Like findControlled, an uncontrolled colony or ship can be given orders.
+func (v *validator) controlled(id string) (*CorS, error) {
+	cs, err := v.find(id)
+	if err != nil {
+		return nil, err
+	} else if cs.ControlledBy != nil && cs.ControlledBy != v.p {
+		return nil, fmt.Errorf("colony or ship %q: %w", id, ErrNotControlled)
+	}
+	return cs, nil
+}
+
+// colocated returns the target if it is in the same orbit as the colony or ship.
+func (v *validator) colocated(cs *CorS, id string) (*CorS, error) {
+	target, err := v.find(id)
+	if err != nil {
+		return nil, err
+	} else if target.Planet == nil || target.Planet != cs.Planet {
+		return nil, fmt.Errorf("%s and %s: %w", cs.HullId, id, ErrNotCoLocated)
+	}
+	return target, nil
+}
+
+// unit returns the unit for a code or name.
+func (v *validator) unit(s string) (*Unit, error) {
+	u, ok := unitFromString(v.e, s)
+	if !ok {
+		return nil, fmt.Errorf("unit %q: %w", s, ErrNotFound)
+	}
+	return u, nil
+}
+
+// inventory returns the inventory for the unit, or nil if the colony or ship doesn't have any.
+func (v *validator) inventory(cs *CorS, u *Unit) *InventoryUnit {
+	for _, iu := range cs.Inventory {
+		if iu.Unit.Id == u.Id {
+			return iu
+		}
+	}
+	return nil
+}
+
+// onHand returns the number of active and stowed units in the colony or ship.
+func (v *validator) onHand(cs *CorS, u *Unit) int {
+	if iu := v.inventory(cs, u); iu != nil {
+		return iu.ActiveQty + iu.StowedQty
+	}
+	return 0
+}
+
+// shortfall returns an error if the quantity available is less than the quantity wanted.
+func (v *validator) shortfall(cs *CorS, what string, want, have int) error {
+	if have < want {
+		return fmt.Errorf("%s: %d %s wanted, %d available: %w", cs.HullId, want, what, have, ErrShortfall)
+	}
+	return nil
+}
+
+// draw adds the quantity wanted to the running total for the colony or ship.
+// It returns an error if the quantity left after the earlier orders is less than the quantity wanted.
+func (v *validator) draw(cs *CorS, what string, want, have int) error {
+	key := cs.HullId + " " + what
+	left := have - v.used[key]
+	if left < 0 {
+		left = 0
+	}
+	v.used[key] += want
+	return v.shortfall(cs, what, want, left)
+}
+
+// population returns the quantity of the population class in the colony or ship.
+func (v *validator) population(cs *CorS, class string) (int, error) {
+	switch class {
+	case "professional":
+		return cs.Population.ProfessionalQty, nil
+	case "soldier":
+		return cs.Population.SoldierQty, nil
+	case "unskilled":
+		return cs.Population.UnskilledQty, nil
+	case "unemployed":
+		return cs.Population.UnemployedQty, nil
+	case "construction-crew":
+		return cs.Population.ConstructionCrewQty, nil
+	case "spy-team":
+		return cs.Population.SpyTeamQty, nil
+	}
+	return 0, fmt.Errorf("population class %q: %w", class, ErrNotFound)
+}
+
+// assemblable returns the unit if the colony or ship has the tech level to assemble it.
+func (v *validator) assemblable(cs *CorS, unit string) (*Unit, error) {
+	u, err := v.unit(unit)
+	if err != nil {
+		return nil, err
+	} else if u.TechLevel > techLevel(cs) {
+		return nil, fmt.Errorf("%s: unit %q: tech level %d: %w", cs.HullId, unit, u.TechLevel, ErrInfeasible)
+	}
+	return u, nil
+}
+
+// product returns the unit if a factory in the colony or ship can produce it.
+func (v *validator) product(cs *CorS, unit string) (*Unit, error) {
+	u, err := v.assemblable(cs, unit)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Kind {
+	case "food", "fuel", "gold", "metallics", "non-metallics":
+		return nil, fmt.Errorf("%s: factories can not produce %q: %w", cs.HullId, unit, ErrInfeasible)
+	}
+	return u, nil
+}
+
+// deposit returns the deposit if it is on the same planet as the colony or ship.
+func (v *validator) deposit(cs *CorS, id string) (*Deposit, error) {
+	if cs.Planet != nil {
+		for _, d := range cs.Planet.Deposits {
+			if id == fmt.Sprintf("DP%d", d.No) {
+				if d.ControlledBy != nil && d.ControlledBy.Id != cs.Id {
+					return nil, fmt.Errorf("%s: deposit %q: %w", cs.HullId, id, ErrNotControlled)
+				}
+				return d, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%s: deposit %q: %w", cs.HullId, id, ErrNotFound)
+}
+
+func (v *validator) attack(o *AttackOrder) error {
+	cs, err := v.controlled(o.CorS)
+	if err != nil {
+		return err
+	}
+	_, err = v.colocated(cs, o.Target)
+	return err
+}
+
+func (v *validator) raid(o *RaidOrder) error {
+	cs, err := v.controlled(o.CorS)
+	if err != nil {
+		return err
+	} else if _, err = v.colocated(cs, o.Target); err != nil {
+		return err
+	}
+	_, err = v.unit(o.Cargo)
+	return err
+}
+
+// stowable checks set up (from stowed units) and disassembly (from active units) orders.
+func (v *validator) stowable(id, unit string, qty int, setUp bool) error {
+	cs, err := v.controlled(id)
+	if err != nil {
+		return err
+	}
+	u, err := v.unit(unit)
+	if err != nil {
+		return err
+	} else if !u.Hudnut {
+		return fmt.Errorf("%s: unit %q can not be disassembled: %w", cs.HullId, unit, ErrInfeasible)
+	}
+	iu := v.inventory(cs, u)
+	if iu == nil {
+		return v.draw(cs, u.Code, qty, 0)
+	} else if setUp {
+		return v.draw(cs, "stowed "+u.Code, qty, iu.StowedQty)
+	}
+	return v.draw(cs, "operational "+u.Code, qty, iu.ActiveQty)
+}
+
+func (v *validator) retoolFactoryGroup(o *RetoolFactoryGroupOrder) error {
+	cs, err := v.controlled(o.CorS)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, group := range cs.FactoryGroups {
+		found = found || o.Group == fmt.Sprintf("FG%d", group.No)
+	}
+	if !found {
+		return fmt.Errorf("%s: factory group %q: %w", cs.HullId, o.Group, ErrNotFound)
+	}
+	_, err = v.product(cs, o.Product)
+	return err
+}
+
+func (v *validator) retoolMiningGroup(o *RetoolMiningGroupOrder) error {
+	cs, err := v.controlled(o.CorS)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, group := range cs.MineGroups {
+		found = found || o.Group == fmt.Sprintf("MG%d", group.No)
+	}
+	if !found {
+		return fmt.Errorf("%s: mine group %q: %w", cs.HullId, o.Group, ErrNotFound)
+	}
+	_, err = v.deposit(cs, o.Deposit)
+	return err
+}
+
+func (v *validator) transferPopulation(o *TransferPopulationOrder) error {
+	from, err := v.controlled(o.From)
+	if err != nil {
+		return err
+	} else if _, err = v.colocated(from, o.To); err != nil {
+		return err
+	}
+	qty, err := v.population(from, o.Class)
+	if err != nil {
+		return err
+	}
+	return v.draw(from, o.Class, o.Quantity, qty)
+}
+
+func (v *validator) transferUnit(o *TransferUnitOrder) error {
+	from, err := v.controlled(o.From)
+	if err != nil {
+		return err
+	} else if _, err = v.colocated(from, o.To); err != nil {
+		return err
+	}
+	u, err := v.unit(o.Unit)
+	if err != nil {
+		return err
+	}
+	return v.draw(from, u.Code, o.Quantity, v.onHand(from, u))
+}
+
+// crew checks construction crew and spy team assembly, which need one professional and one unskilled worker each.
+func (v *validator) crew(id string, qty int) error {
+	cs, err := v.controlled(id)
+	if err != nil {
+		return err
+	} else if err = v.draw(cs, "professional", qty, cs.Population.ProfessionalQty); err != nil {
+		return err
+	}
+	return v.draw(cs, "unskilled", qty, cs.Population.UnskilledQty)
+}
+
+// group checks the units that will be assembled into a factory, farm, or mine group.
+func (v *validator) group(id, unit string, qty int) (*CorS, error) {
+	cs, err := v.controlled(id)
+	if err != nil {
+		return nil, err
+	}
+	u, err := v.assemblable(cs, unit)
+	if err != nil {
+		return nil, err
+	}
+	var stowed int
+	if iu := v.inventory(cs, u); iu != nil {
+		stowed = iu.StowedQty
+	}
+	return cs, v.draw(cs, "stowed "+u.Code, qty, stowed)
+}
+
+func (v *validator) assembleFactoryGroup(o *AssembleFactoryGroupOrder) error {
+	cs, err := v.group(o.CorS, o.Unit, o.Quantity)
+	if err != nil {
+		return err
+	}
+	_, err = v.product(cs, o.Product)
+	return err
+}
+
+func (v *validator) assembleFarmGroup(o *AssembleFarmGroupOrder) error {
+	cs, err := v.group(o.CorS, o.Unit, o.Quantity)
+	if err != nil {
+		return err
+	}
+	product, err := v.assemblable(cs, o.Product)
+	if err != nil {
+		return err
+	} else if product.Kind != "food" {
+		return fmt.Errorf("%s: farms can not produce %q: %w", cs.HullId, o.Product, ErrInfeasible)
+	}
+	return nil
+}
+
+func (v *validator) assembleMineGroup(o *AssembleMineGroupOrder) error {
+	cs, err := v.group(o.CorS, o.Unit, o.Quantity)
+	if err != nil {
+		return err
+	}
+	_, err = v.deposit(cs, o.Deposit)
+	return err
+}
+
+// hull checks the structural units for a new colony or ship.
+func (v *validator) hull(cs *CorS, unit string, qty int) error {
+	u, err := v.unit(unit)
+	if err != nil {
+		return err
+	} else if !(u.Code == "STUN" || u.Code == "LTSU" || u.Code == "SLSU") {
+		return fmt.Errorf("%s: %q is not a structural unit: %w", cs.HullId, unit, ErrInfeasible)
+	}
+	var stowed int
+	if iu := v.inventory(cs, u); iu != nil {
+		stowed = iu.StowedQty
+	}
+	return v.draw(cs, "stowed "+u.Code, qty, stowed)
+}
+
+func (v *validator) buildColony(o *BuildColonyOrder) error {
+	cs, err := v.controlled(o.CorS)
+	if err != nil {
+		return err
+	} else if cs.Planet == nil || cs.Planet.System.Coords != o.Coords {
+		return fmt.Errorf("%s and %s: %w", cs.HullId, o.Coords.String(), ErrNotCoLocated)
+	} else if _, ok := v.e.findPlanet(o.Coords, o.Star, o.OrbitNo); !ok {
+		return fmt.Errorf("location %s%s#%d: %w", o.Coords.String(), o.Star, o.OrbitNo, ErrNotFound)
+	}
+	return v.hull(cs, o.Unit, o.Quantity)
+}
+
+func (v *validator) buildShip(o *BuildShipOrder) error {
+	cs, err := v.controlled(o.CorS)
+	if err != nil {
+		return err
+	}
+	return v.hull(cs, o.Unit, o.Quantity)
+}
+
+func (v *validator) buy(o *BuyOrder) error {
+	cs, err := v.controlled(o.CorS)
+	if err != nil {
+		return err
+	} else if _, err = v.unit(o.Unit); err != nil {
+		return err
+	}
+	for _, gold := range v.e.Units {
+		if gold.Kind == "gold" {
+			return v.draw(cs, gold.Code, o.Quantity*o.Price, v.onHand(cs, gold))
+		}
+	}
+	return nil
+}
+
+func (v *validator) offer(o *OfferOrder) error {
+	cs, err := v.controlled(o.CorS)
+	if err != nil {
+		return err
+	} else if _, err = v.colocated(cs, o.Partner); err != nil {
+		return err
+	} else if _, err = v.unit(o.WantUnit); err != nil {
+		return err
+	}
+	u, err := v.unit(o.Unit)
+	if err != nil {
+		return err
+	}
+	return v.draw(cs, u.Code, o.Quantity, v.onHand(cs, u))
+}
+
+func (v *validator) sell(o *SellOrder) error {
+	cs, err := v.controlled(o.CorS)
+	if err != nil {
+		return err
+	}
+	u, err := v.unit(o.Unit)
+	if err != nil {
+		return err
+	}
+	return v.draw(cs, u.Code, o.Quantity, v.onHand(cs, u))
+}
+
+func (v *validator) survey(o *SurveyOrder) error {
+	cs, err := v.controlled(o.CorS)
+	if err != nil {
+		return err
+	} else if cs.Planet == nil || cs.Planet.System.Coords != o.Coords {
+		return fmt.Errorf("%s and %s: %w", cs.HullId, o.Coords.String(), ErrNotCoLocated)
+	}
+	return nil
+}
+
+// spies checks espionage orders. Spies can only reach targets in the same system.
+func (v *validator) spies(id string, qty int, targetId string) error {
+	cs, err := v.controlled(id)
+	if err != nil {
+		return err
+	} else if err = v.draw(cs, "spy-team", qty, cs.Population.SpyTeamQty); err != nil {
+		return err
+	} else if targetId == "" {
+		return nil
+	}
+	target, err := v.find(targetId)
+	if err != nil {
+		return err
+	} else if cs.Planet == nil || target.Planet == nil || target.Planet.System != cs.Planet.System {
+		return fmt.Errorf("%s and %s: %w", cs.HullId, targetId, ErrNotCoLocated)
+	}
+	return nil
+}
+
+// ship returns the ship if it can be given orders by the player.
+func (v *validator) ship(id string) (*CorS, error) {
+	s, ok := v.e.findShip(id)
+	if !ok {
+		return nil, fmt.Errorf("ship %q: %w", id, ErrNotFound)
+	} else if s.ControlledBy != nil && s.ControlledBy != v.p {
+		return nil, fmt.Errorf("ship %q: %w", id, ErrNotControlled)
+	}
+	return s, nil
+}
+
+func (v *validator) jump(o *JumpShipOrder) error {
+	s, err := v.ship(o.Id)
+	if err != nil {
+		return err
+	}
+	dest, ok := v.e.findPlanet(o.Coords, o.Star, o.OrbitNo)
+	if !ok {
+		return fmt.Errorf("location %s%s#%d: %w", o.Coords.String(), o.Star, o.OrbitNo, ErrNotFound)
+	} else if s.Planet != nil && dest.System == s.Planet.System {
+		return fmt.Errorf("%s: jump within system: %w", s.HullId, ErrInfeasible)
+	}
+	return v.fuel(s, "hyper-drive", hyperDriveThrust)
+}
+
+func (v *validator) move(o *MoveShipOrder) error {
+	s, err := v.ship(o.Id)
+	if err != nil {
+		return err
+	} else if s.Planet == nil {
+		return fmt.Errorf("%s: not in orbit: %w", s.HullId, ErrInfeasible)
+	}
+	coords, star := s.Planet.System.Coords, s.Planet.Star.Sequence
+	if o.Coords != nil && *o.Coords != coords {
+		return fmt.Errorf("%s and %s: %w", s.HullId, o.Coords.String(), ErrNotCoLocated)
+	} else if o.Star != "" {
+		star = o.Star
+	}
+	if _, ok := v.e.findPlanet(coords, star, o.OrbitNo); !ok {
+		return fmt.Errorf("location %s%s#%d: %w", coords.String(), star, o.OrbitNo, ErrNotFound)
+	}
+	return v.fuel(s, "space-drive", spaceDriveThrust)
+}
+
+// fuel checks the fuel needed to run the ship's drives of the given kind.
+// Energy from solar arrays isn't known until the turn runs, so only the fuel in inventory is counted.
+func (v *validator) fuel(s *CorS, kind string, thrust float64) error {
+	_, fuelNeeded := driveCapacity(s, kind, thrust)
+	for _, fuel := range v.e.Units {
+		if fuel.Kind == "fuel" {
+			return v.draw(s, fuel.Code, fuelNeeded, v.onHand(s, fuel))
+		}
+	}
+	return nil
+}
+
+func (v *validator) draft(o *DraftOrder) error {
+	cs, err := v.controlled(o.CorS)
+	if err != nil {
+		return err
+	}
+	unitsPerTrainee := 1
+	switch o.Class {
+	case "professional", "soldier", "unskilled":
+	case "construction-crew":
+		unitsPerTrainee = 2
+	default:
+		return fmt.Errorf("population class %q: %w", o.Class, ErrNotFound)
+	}
+	return v.draw(cs, "unemployed", o.Quantity*unitsPerTrainee, int(draftRate*float64(cs.Population.UnemployedQty)))
+}
+
+func (v *validator) research(o *ResearchOrder) error {
+	if v.p == nil || v.p.MemberOf == nil {
+		return fmt.Errorf("research %s: player is not a member of a nation: %w", o.Target, ErrInfeasible)
+	} else if _, ok := researchLevel(v.p.MemberOf, o.Target); !ok {
+		return fmt.Errorf("research %q: %w", strings.ToLower(o.Target), ErrNotFound)
+	}
+	return nil
+}
+
+// controlColony checks that the colony exists and isn't already controlled by another player.
+func (v *validator) controlColony(id string) error {
+	c, ok := v.e.findColony(id)
+	if !ok {
+		return fmt.Errorf("colony %q: %w", id, ErrNotFound)
+	} else if c.ControlledBy != nil && c.ControlledBy != v.p {
+		return fmt.Errorf("colony %q: %w", id, ErrNotControlled)
+	}
+	return nil
+}
+
+func (v *validator) maintain(o *MaintainOrder) error {
+	cs, err := v.controlled(o.CorS)
+	if err != nil {
+		return err
+	}
+	u, err := v.unit(o.Unit)
+	if err != nil {
+		return err
+	} else if v.onHand(cs, u) == 0 {
+		return fmt.Errorf("%s: unit %q: %w", cs.HullId, o.Unit, ErrNotFound)
+	}
+	return nil
+}
+
+func (v *validator) condition(c *Condition) error {
+	if _, err := v.controlled(c.CorS); err != nil {
+		return err
+	}
+	_, err := v.unit(c.Unit)
+	return err
+}
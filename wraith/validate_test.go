@@ -0,0 +1,151 @@
+////////////////////////////////////////////////////////////////////////////////
+// wraith - the wraith game engine and server
+// Copyright (c) 2022 Michael D. Henderson
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+////////////////////////////////////////////////////////////////////////////////
+
+package wraith
+
+import (
+	"errors"
+	"testing"
+)
+
+// validationEngine returns a game with two colonies and a ship in orbit around the same planet.
+// C1 has 100 FUEL, 10 professionals, 10 unskilled workers, and 5 spy teams.
+// S3 has 10 space-drives that burn 10 FUEL a turn and 15 FUEL in its hold.
+// C4 belongs to another player.
+func validationEngine() (*Engine, *Player) {
+	fuel := &Unit{Id: 1, Kind: "fuel", Code: "FUEL", Name: "fuel", MassPerUnit: 1}
+	drive := &Unit{Id: 2, Kind: "space-drive", Code: "SDR-1", Name: "space-drive-1", TechLevel: 1, MassPerUnit: 1, FuelPerUnitPerTurn: 1}
+	system := &System{Id: 1, Coords: Coordinates{X: 1, Y: 2, Z: 3}}
+	star := &Star{Id: 1, System: system, Sequence: "A"}
+	system.Stars = []*Star{star}
+	for no := 1; no <= 2; no++ {
+		star.Planets = append(star.Planets, &Planet{Id: no, System: system, Star: star, OrbitNo: no})
+	}
+	p, other := &Player{Id: 1, Name: "alpha"}, &Player{Id: 2, Name: "beta"}
+	c1 := &CorS{Id: 1, HullId: "C1", Kind: "surface", ControlledBy: p, Planet: star.Planets[0],
+		Inventory:  InventoryUnits{{Unit: fuel, StowedQty: 100}},
+		Population: Population{ProfessionalQty: 10, UnskilledQty: 10, SpyTeamQty: 5},
+	}
+	c2 := &CorS{Id: 2, HullId: "C2", Kind: "surface", ControlledBy: p, Planet: star.Planets[0]}
+	s3 := &CorS{Id: 3, HullId: "S3", Kind: "ship", ControlledBy: p, Planet: star.Planets[0],
+		Hull:      InventoryUnits{{Unit: drive, ActiveQty: 10}},
+		Inventory: InventoryUnits{{Unit: fuel, StowedQty: 15}},
+	}
+	c4 := &CorS{Id: 4, HullId: "C4", Kind: "surface", ControlledBy: other, Planet: star.Planets[0]}
+	e := &Engine{
+		Colonies:        map[string]*CorS{"C1": c1, "C2": c2, "C4": c4},
+		Ships:           map[string]*CorS{"S3": s3},
+		Systems:         map[int]*System{1: system},
+		Units:           map[int]*Unit{1: fuel, 2: drive},
+		UnitsFromString: map[string]*Unit{"FUEL": fuel, "fuel": fuel, "SDR-1": drive, "space-drive-1": drive},
+	}
+	return e, p
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		orders []*PhaseOrders // each one holds a single order
+		want   []string       // error expected for each order, empty for none
+	}{
+		{name: "units within stock",
+			orders: []*PhaseOrders{
+				{Transfer: []*TransferPhaseOrder{{Unit: &TransferUnitOrder{From: "C1", To: "C2", Quantity: 60, Unit: "FUEL"}}}},
+				{Trade: []*TradePhaseOrder{{Sell: &SellOrder{CorS: "C1", Quantity: 40, Unit: "FUEL", Price: 1}}}},
+			},
+			want: []string{"", ""},
+		},
+		{name: "units overdrawn together",
+			// the transfer phase runs before the trade phase, so the sale comes up short
+			orders: []*PhaseOrders{
+				{Trade: []*TradePhaseOrder{{Sell: &SellOrder{CorS: "C1", Quantity: 60, Unit: "FUEL", Price: 1}}}},
+				{Transfer: []*TransferPhaseOrder{{Unit: &TransferUnitOrder{From: "C1", To: "C2", Quantity: 60, Unit: "FUEL"}}}},
+			},
+			want: []string{"C1: 60 FUEL wanted, 40 available: not enough on hand", ""},
+		},
+		{name: "each colony or ship has its own stock",
+			orders: []*PhaseOrders{
+				{Trade: []*TradePhaseOrder{{Sell: &SellOrder{CorS: "C1", Quantity: 100, Unit: "FUEL", Price: 1}}}},
+				{Trade: []*TradePhaseOrder{{Sell: &SellOrder{CorS: "S3", Quantity: 15, Unit: "FUEL", Price: 1}}}},
+			},
+			want: []string{"", ""},
+		},
+		{name: "crews overdrawn together",
+			orders: []*PhaseOrders{
+				{Assembly: []*AssemblyPhaseOrder{{ConstructionCrew: &AssembleConstructionCrewOrder{CorS: "C1", Quantity: 6}}}},
+				{Assembly: []*AssemblyPhaseOrder{{SpyTeam: &AssembleSpyTeamOrder{CorS: "C1", Quantity: 6}}}},
+			},
+			want: []string{"", "C1: 6 professional wanted, 4 available: not enough on hand"},
+		},
+		{name: "spy teams overdrawn together",
+			orders: []*PhaseOrders{
+				{Espionage: []*EspionagePhaseOrder{{CounterIntel: &CounterIntelOrder{CorS: "C1", Quantity: 3}}}},
+				{Espionage: []*EspionagePhaseOrder{{GatherIntel: &GatherIntelOrder{CorS: "C1", Quantity: 3, Target: "C2"}}}},
+			},
+			want: []string{"", "C1: 3 spy-team wanted, 2 available: not enough on hand"},
+		},
+		{name: "fuel for the drives",
+			orders: []*PhaseOrders{
+				{Movement: []*MovementPhaseOrder{{Move: &MoveShipOrder{Id: "S3", OrbitNo: 2}}}},
+			},
+			want: []string{""},
+		},
+		{name: "fuel overdrawn together",
+			orders: []*PhaseOrders{
+				{Movement: []*MovementPhaseOrder{{Move: &MoveShipOrder{Id: "S3", OrbitNo: 2}}}},
+				{Transfer: []*TransferPhaseOrder{{Unit: &TransferUnitOrder{From: "S3", To: "C1", Quantity: 10, Unit: "FUEL"}}}},
+			},
+			want: []string{"S3: 10 FUEL wanted, 5 available: not enough on hand", ""},
+		},
+		{name: "errors are kept with their order",
+			orders: []*PhaseOrders{
+				{Control: []*ControlPhaseOrder{{NameColony: &NameColonyOrder{Id: "C1", Name: "Alpha"}}}},
+				{Control: []*ControlPhaseOrder{{NameColony: &NameColonyOrder{Id: "C4", Name: "Beta"}}}},
+				{Control: []*ControlPhaseOrder{{NameColony: &NameColonyOrder{Id: "C9", Name: "Gamma"}}}},
+			},
+			want: []string{"", `colony or ship "C4": not controlled by player`, `colony or ship "C9": not found`},
+		},
+	} {
+		e, p := validationEngine()
+		po, index := &PhaseOrders{Player: p}, make(map[interface{}]int)
+		for i, o := range tc.orders {
+			for _, added := range po.Add(o) {
+				index[added] = i
+			}
+		}
+		got := make([]string, len(tc.orders))
+		for _, err := range e.Validate(po) {
+			var oe *OrderError
+			if !errors.As(err, &oe) {
+				t.Errorf("%s: %v: want *OrderError: got %T", tc.name, err, err)
+				continue
+			}
+			i, ok := index[oe.Order]
+			if !ok {
+				t.Errorf("%s: %v: order not found", tc.name, err)
+				continue
+			}
+			got[i] = err.Error()
+		}
+		for i, want := range tc.want {
+			if want != got[i] {
+				t.Errorf("%s: order %d: want %q: got %q", tc.name, i+1, want, got[i])
+			}
+		}
+	}
+}